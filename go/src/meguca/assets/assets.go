@@ -62,6 +62,12 @@ func ThumbPath(thumbType uint8, SHA1 string) string {
 	return imagePath(imageRoot(), "thumb", thumbType, SHA1)
 }
 
+// RelativePath strips the image root from a path returned by SourcePath
+// or ThumbPath
+func RelativePath(path string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(path, imageRoot()), "/")
+}
+
 func ThumbPathLocal(thumbType uint8, SHA1 string) string {
 	return imagePath("/uploads", "thumb", thumbType, SHA1)
 }
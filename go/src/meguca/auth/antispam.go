@@ -4,6 +4,8 @@ import (
 	"errors"
 	"sync"
 	"time"
+
+	"meguca/config"
 )
 
 // Score values of various actions
@@ -16,6 +18,9 @@ const (
 
 	// Image insertion score
 	ImageScore = time.Second * 20
+
+	// Link to an external site
+	LinkScore = time.Second * 5
 )
 
 var (
@@ -32,7 +37,7 @@ type spamCounterMap struct {
 	m map[string]*spamCounter
 }
 
-func (m *spamCounterMap) get(ip string) *spamCounter {
+func (m *spamCounterMap) get(ip string, offset time.Duration) *spamCounter {
 	m.Lock()
 	defer m.Unlock()
 
@@ -41,7 +46,7 @@ func (m *spamCounterMap) get(ip string) *spamCounter {
 		s = &spamCounter{
 			ip: ip,
 		}
-		s.init()
+		s.init(offset)
 		m.m[ip] = s
 	}
 	return s
@@ -84,9 +89,10 @@ type spamCounter struct {
 	counter time.Time
 }
 
-// Set the counter to an initial position
-func (s *spamCounter) init() {
-	s.counter = time.Now().Add(-time.Minute)
+// Set the counter to an initial position. offset is the score allowed
+// before a captcha is required.
+func (s *spamCounter) init(offset time.Duration) {
+	s.counter = time.Now().Add(-offset)
 }
 
 // Can this IP create a new post?
@@ -98,53 +104,72 @@ func (s *spamCounter) canPost() bool {
 
 // Increment spam detection score, after performing an action.
 // Returns, if the limit was exceeded.
-func (s *spamCounter) increment(by time.Duration) (bool, error) {
+func (s *spamCounter) increment(by, offset, max time.Duration) (bool, error) {
 	now := time.Now()
 	s.Lock()
 	defer s.Unlock()
 
 	// Keep score from desending bellow offset or initialize
-	if now.Sub(s.counter) > time.Minute {
-		s.init()
+	if now.Sub(s.counter) > offset {
+		s.init(offset)
 	}
 	s.counter = s.counter.Add(by)
 
-	if s.counter.Sub(now) > time.Minute*10 {
+	if s.counter.Sub(now) > max-offset {
 		// This surely is not done by normal human interaction
 		return true, ErrSpamDected
 	}
 	return s.counter.After(now), nil
 }
 
-func (s *spamCounter) reset() {
+func (s *spamCounter) reset(offset time.Duration) {
 	s.Lock()
 	defer s.Unlock()
-	s.init()
+	s.init(offset)
+}
+
+// Returns the captcha and rejection score thresholds. ok = false, if spam
+// detection is disabled.
+func spamThresholds() (offset, max time.Duration, ok bool) {
+	conf := config.Get()
+	if conf == nil || conf.Antispam.CaptchaScore <= 0 {
+		return
+	}
+	offset = time.Duration(conf.Antispam.CaptchaScore) * time.Second
+	max = time.Duration(conf.Antispam.MaxScore) * time.Second
+	if max < offset {
+		max = offset
+	}
+	ok = true
+	return
 }
 
 // Returns, if the user does not trigger antispam
 func CanPost(ip string) bool {
-	if true { //!config.Get().Captcha {
+	offset, _, ok := spamThresholds()
+	if !ok {
 		return true
 	}
-	return spamCounters.get(ip).canPost()
+	return spamCounters.get(ip, offset).canPost()
 }
 
 // Increment spam detection score to an IP, after performing an action.
 // Returns, if the limit was exceeded.
 func IncrementSpamScore(ip string, score time.Duration) (bool, error) {
-	if true { //!config.Get().Captcha {
+	offset, max, ok := spamThresholds()
+	if !ok {
 		return false, nil
 	}
-	return spamCounters.get(ip).increment(score)
+	return spamCounters.get(ip, offset).increment(score, offset, max)
 }
 
 // Reset a spam score to zero by IP
 func ResetSpamScore(ip string) {
-	if true { //!config.Get().Captcha {
+	offset, _, ok := spamThresholds()
+	if !ok {
 		return
 	}
-	spamCounters.get(ip).reset()
+	spamCounters.get(ip, offset).reset(offset)
 }
 
 // Clear all spam detection data. Only use for tests.
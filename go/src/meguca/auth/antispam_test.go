@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"meguca/config"
+)
+
+func setAntispam(t *testing.T, captchaScore, maxScore int) {
+	t.Helper()
+	conf := config.DefaultServerConfig
+	conf.Antispam.CaptchaScore = captchaScore
+	conf.Antispam.MaxScore = maxScore
+	if err := config.Set(conf); err != nil {
+		t.Fatal(err)
+	}
+	ClearSpamCounters()
+}
+
+func TestSpamScore(t *testing.T) {
+	defer setAntispam(t, 0, 0)
+
+	cases := [...]struct {
+		name                   string
+		captchaScore, maxScore int
+		scores                 []time.Duration
+		exceeded, canPost      bool
+		err                    error
+	}{
+		{
+			name:    "disabled",
+			scores:  []time.Duration{time.Hour},
+			canPost: true,
+		},
+		{
+			name:         "below captcha score",
+			captchaScore: 60,
+			maxScore:     600,
+			scores:       []time.Duration{PostCreationScore, 3 * LinkScore},
+			canPost:      true,
+		},
+		{
+			name:         "captcha score exceeded",
+			captchaScore: 60,
+			maxScore:     600,
+			scores: []time.Duration{
+				PostCreationScore,
+				PostCreationScore + 2*ImageScore,
+				PostCreationScore,
+			},
+			exceeded: true,
+		},
+		{
+			name:         "max score exceeded",
+			captchaScore: 60,
+			maxScore:     600,
+			scores:       []time.Duration{11 * time.Minute},
+			exceeded:     true,
+			err:          ErrSpamDected,
+		},
+		{
+			name:         "max score below captcha score",
+			captchaScore: 60,
+			maxScore:     10,
+			scores:       []time.Duration{61 * time.Second},
+			exceeded:     true,
+			err:          ErrSpamDected,
+		},
+	}
+
+	const ip = "::1"
+	for i := range cases {
+		c := cases[i]
+		t.Run(c.name, func(t *testing.T) {
+			setAntispam(t, c.captchaScore, c.maxScore)
+
+			var (
+				exceeded bool
+				err      error
+			)
+			for _, s := range c.scores {
+				exceeded, err = IncrementSpamScore(ip, s)
+			}
+			if exceeded != c.exceeded {
+				t.Errorf("exceeded: expected %v, got %v", c.exceeded, exceeded)
+			}
+			if err != c.err {
+				t.Errorf("error: expected %v, got %v", c.err, err)
+			}
+			if can := CanPost(ip); can != c.canPost {
+				t.Errorf("can post: expected %v, got %v", c.canPost, can)
+			}
+			if !CanPost("::2") {
+				t.Error("other IP can't post")
+			}
+		})
+	}
+}
+
+func TestResetSpamScore(t *testing.T) {
+	setAntispam(t, 60, 600)
+	defer setAntispam(t, 0, 0)
+
+	const ip = "::1"
+	if exceeded, _ := IncrementSpamScore(ip, 2*time.Minute); !exceeded {
+		t.Fatal("captcha score not exceeded")
+	}
+	ResetSpamScore(ip)
+	if !CanPost(ip) {
+		t.Fatal("score not reset")
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"net"
 	"net/http"
 	"strings"
-	"sync"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -23,10 +22,6 @@ var (
 	// for filtering in XFF IP determination.
 	ReverseProxyIP string
 
-	// board: IP: IsBanned
-	bans   = map[string]map[string]bool{}
-	bansMu sync.RWMutex
-
 	NullPositions = Positions{CurBoard: NotLoggedIn, AnyBoard: NotLoggedIn}
 )
 
@@ -103,34 +98,3 @@ func BcryptHash(password string, rounds int) ([]byte, error) {
 func BcryptCompare(password string, hash []byte) error {
 	return bcrypt.CompareHashAndPassword(hash, []byte(password))
 }
-
-// IsBanned returns if the IP is banned on the target board
-func IsBanned(board, ip string) (banned bool) {
-	bansMu.RLock()
-	defer bansMu.RUnlock()
-	global := bans["all"]
-	ips := bans[board]
-	if global != nil && global[ip] {
-		return true
-	}
-	if ips != nil && ips[ip] {
-		return true
-	}
-	return false
-}
-
-// SetBans replaces the ban cache with the new set
-func SetBans(b ...Ban) {
-	newBans := map[string]map[string]bool{}
-	for _, b := range b {
-		board, ok := newBans[b.Board]
-		if !ok {
-			board = map[string]bool{}
-			newBans[b.Board] = board
-		}
-		board[b.IP] = true
-	}
-	bansMu.Lock()
-	bans = newBans
-	bansMu.Unlock()
-}
@@ -0,0 +1,197 @@
+package auth
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"sync"
+)
+
+// Default prefix lengths of range bans
+const (
+	DefaultIPv4Prefix = 24
+	DefaultIPv6Prefix = 48
+)
+
+// Shortest allowed prefix lengths of range bans. Prevents banning
+// significant parts of the internet by mistake.
+const (
+	MinIPv4Prefix = 8
+	MinIPv6Prefix = 16
+)
+
+var (
+	// board: cached bans
+	bans   = map[string]*boardBans{}
+	bansMu sync.RWMutex
+
+	ErrInvalidBanOptions = errors.New("invalid ban options")
+)
+
+// BanOptions specifies, how new bans are matched against clients
+type BanOptions struct {
+	Scope BanScope
+	// Prefix lengths for BanRange. Zero values use the defaults.
+	IPv4Prefix, IPv6Prefix int
+}
+
+// Validate checks the scope and prefix lengths are in allowed ranges
+func (o BanOptions) Validate() error {
+	switch {
+	case o.Scope > BanUniqueID,
+		o.IPv4Prefix != 0 &&
+			(o.IPv4Prefix < MinIPv4Prefix || o.IPv4Prefix > 32),
+		o.IPv6Prefix != 0 &&
+			(o.IPv6Prefix < MinIPv6Prefix || o.IPv6Prefix > 128):
+		return ErrInvalidBanOptions
+	}
+	return nil
+}
+
+// Target returns the address or network to write to the ban table for an
+// IP. For BanRange that is the network containing the IP in CIDR notation.
+func (o BanOptions) Target(ip string) (string, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return "", ErrInvalidBanOptions
+	}
+	if o.Scope != BanRange {
+		return parsed.String(), nil
+	}
+
+	bits, prefix := 128, o.IPv6Prefix
+	if prefix == 0 {
+		prefix = DefaultIPv6Prefix
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		parsed = v4
+		bits, prefix = 32, o.IPv4Prefix
+		if prefix == 0 {
+			prefix = DefaultIPv4Prefix
+		}
+	}
+	mask := net.CIDRMask(prefix, bits)
+	n := net.IPNet{
+		IP:   parsed.Mask(mask),
+		Mask: mask,
+	}
+	return n.String(), nil
+}
+
+// Prefix length and address size in bits of a banned network
+type netPrefix struct {
+	ones, bits int
+}
+
+// Network in the default range of an IP. Unique IDs are only hashed from
+// request headers, so unrelated clients can share them. Unique ID bans are
+// matched only within this range around the banned IP.
+func uniqueIDRange(ip string) string {
+	n, _ := BanOptions{Scope: BanRange}.Target(ip)
+	return n
+}
+
+// Bans of a single board indexed for lookup
+type boardBans struct {
+	ips map[string]bool
+	// unique ID: network: IsBanned
+	uniqueIDs map[string]map[string]bool
+	// prefix: network: IsBanned. There are only a few distinct prefixes in
+	// use, so an IP is checked with one lookup per prefix.
+	ranges map[netPrefix]map[string]bool
+}
+
+func newBoardBans() *boardBans {
+	return &boardBans{
+		ips:       make(map[string]bool),
+		uniqueIDs: make(map[string]map[string]bool),
+		ranges:    make(map[netPrefix]map[string]bool),
+	}
+}
+
+func (b *boardBans) add(ban Ban) {
+	if strings.IndexByte(ban.IP, '/') != -1 {
+		_, n, err := net.ParseCIDR(ban.IP)
+		if err != nil {
+			return
+		}
+		ones, bits := n.Mask.Size()
+		p := netPrefix{ones, bits}
+		nets := b.ranges[p]
+		if nets == nil {
+			nets = make(map[string]bool)
+			b.ranges[p] = nets
+		}
+		nets[n.IP.String()] = true
+	} else if ip := net.ParseIP(ban.IP); ip != nil {
+		b.ips[ip.String()] = true
+	}
+	if ban.Scope == BanUniqueID && ban.UniqueID != "" {
+		n := uniqueIDRange(ban.IP)
+		if n == "" {
+			return
+		}
+		nets := b.uniqueIDs[ban.UniqueID]
+		if nets == nil {
+			nets = make(map[string]bool)
+			b.uniqueIDs[ban.UniqueID] = nets
+		}
+		nets[n] = true
+	}
+}
+
+func (b *boardBans) matches(ip net.IP, uniqueID string) bool {
+	if b == nil {
+		return false
+	}
+	if b.ips[ip.String()] {
+		return true
+	}
+	if nets := b.uniqueIDs[uniqueID]; nets != nil &&
+		nets[uniqueIDRange(ip.String())] {
+		return true
+	}
+	if len(b.ranges) == 0 {
+		return false
+	}
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	for p, nets := range b.ranges {
+		if p.bits != len(ip)*8 {
+			continue
+		}
+		if nets[ip.Mask(net.CIDRMask(p.ones, p.bits)).String()] {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBanned returns if the IP or unique ID is banned on the target board
+func IsBanned(board, ip, uniqueID string) (banned bool) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	bansMu.RLock()
+	defer bansMu.RUnlock()
+	return bans["all"].matches(parsed, uniqueID) ||
+		bans[board].matches(parsed, uniqueID)
+}
+
+// SetBans replaces the ban cache with the new set
+func SetBans(b ...Ban) {
+	newBans := map[string]*boardBans{}
+	for _, b := range b {
+		board, ok := newBans[b.Board]
+		if !ok {
+			board = newBoardBans()
+			newBans[b.Board] = board
+		}
+		board.add(b)
+	}
+	bansMu.Lock()
+	bans = newBans
+	bansMu.Unlock()
+}
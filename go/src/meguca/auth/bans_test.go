@@ -0,0 +1,79 @@
+package auth
+
+import "testing"
+
+func TestIsBanned(t *testing.T) {
+	SetBans(
+		Ban{IP: "10.0.0.1", Board: "a"},
+		Ban{IP: "192.168.1.0/24", Board: "a", Scope: BanRange},
+		Ban{IP: "2001:db8:abcd::/48", Board: "a", Scope: BanRange},
+		Ban{
+			IP:       "172.16.0.1",
+			Board:    "a",
+			UniqueID: "abcdefghij",
+			Scope:    BanUniqueID,
+		},
+		Ban{IP: "8.8.8.8", Board: "all"},
+	)
+	defer SetBans()
+
+	cases := [...]struct {
+		name, board, ip, uniqueID string
+		banned                    bool
+	}{
+		{"single IP", "a", "10.0.0.1", "", true},
+		{"other board", "b", "10.0.0.1", "", false},
+		{"global", "b", "8.8.8.8", "", true},
+		{"IPv4 range", "a", "192.168.1.200", "", true},
+		{"outside IPv4 range", "a", "192.168.2.1", "", false},
+		{"IPv6 range", "a", "2001:db8:abcd:12::1", "", true},
+		{"outside IPv6 range", "a", "2001:db8:abce::1", "", false},
+		{"unique ID", "a", "172.16.0.2", "abcdefghij", true},
+		{"unique ID outside range", "a", "10.0.0.2", "abcdefghij", false},
+		{"unique ID ban IP", "a", "172.16.0.1", "", true},
+		{"not banned", "a", "10.0.0.2", "0123456789", false},
+	}
+
+	for i := range cases {
+		c := cases[i]
+		t.Run(c.name, func(t *testing.T) {
+			if IsBanned(c.board, c.ip, c.uniqueID) != c.banned {
+				t.Fatal("unexpected result")
+			}
+		})
+	}
+}
+
+func TestBanTarget(t *testing.T) {
+	cases := [...]struct {
+		name, ip, out string
+		opts          BanOptions
+	}{
+		{"single IP", "10.0.0.1", "10.0.0.1", BanOptions{}},
+		{
+			"default IPv4 range", "10.0.3.1", "10.0.3.0/24",
+			BanOptions{Scope: BanRange},
+		},
+		{
+			"IPv4 range", "10.0.3.1", "10.0.0.0/16",
+			BanOptions{Scope: BanRange, IPv4Prefix: 16},
+		},
+		{
+			"default IPv6 range", "2001:db8:abcd:12::1", "2001:db8:abcd::/48",
+			BanOptions{Scope: BanRange},
+		},
+	}
+
+	for i := range cases {
+		c := cases[i]
+		t.Run(c.name, func(t *testing.T) {
+			out, err := c.opts.Target(c.ip)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out != c.out {
+				t.Fatalf("expected %s, got %s", c.out, out)
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@
 
 package auth
 
+import "strconv"
+
 // ModerationLevel defines the level required to perform an action
 type ModerationLevel int8
 
@@ -74,10 +76,25 @@ func (staff *Staff) TryMarshal() []byte {
 	return data
 }
 
+// BanScope specifies, what clients a ban is matched against
+type BanScope uint8
+
+const (
+	// Single IP address
+	BanIP BanScope = iota
+	// IPv4 or IPv6 network prefix
+	BanRange
+	// Unique ID hashed from the client's request headers. Only matched within
+	// the default range of the banned IP, which is banned as well.
+	BanUniqueID
+)
+
 // Ban holdsan entry of an IP being banned from a board
 type Ban struct {
-	IP    string `json:"ip"`
-	Board string `json:"board"`
+	IP       string   `json:"ip"`
+	Board    string   `json:"board"`
+	UniqueID string   `json:"uniqueID,omitempty"`
+	Scope    BanScope `json:"scope"`
 }
 
 // BanRecord stores information about a specific ban
@@ -114,8 +131,59 @@ const (
 	SpoilerImage
 	DeleteThread
 	UpdateBoard
+	SelfDeletePost
+	StickyThread
+	UnstickyThread
+	LockThread
+	UnlockThread
+	MoveThread
+	MergeThread
+	AcceptAppeal
+	DenyAppeal
+	UndeletePost
+	NukePosts
 )
 
+var moderationActionNames = [...]string{
+	"banPost",
+	"unbanPost",
+	"deletePost",
+	"deleteImage",
+	"spoilerImage",
+	"deleteThread",
+	"updateBoard",
+	"selfDeletePost",
+	"stickyThread",
+	"unstickyThread",
+	"lockThread",
+	"unlockThread",
+	"moveThread",
+	"mergeThread",
+	"acceptAppeal",
+	"denyAppeal",
+	"undeletePost",
+	"nukePosts",
+}
+
+// Returns string representation of moderation action
+func (a ModerationAction) String() string {
+	if int(a) < len(moderationActionNames) {
+		return moderationActionNames[a]
+	}
+	return strconv.Itoa(int(a))
+}
+
+// ParseModerationAction parses an action from its name as returned by
+// String()
+func ParseModerationAction(s string) (ModerationAction, bool) {
+	for i, n := range moderationActionNames {
+		if n == s {
+			return ModerationAction(i), true
+		}
+	}
+	return 0, false
+}
+
 // Single entry in the moderation log
 type ModLogRecord struct {
 	Board   string           `json:"board"`
@@ -123,11 +191,95 @@ type ModLogRecord struct {
 	Type    ModerationAction `json:"type"`
 	By      string           `json:"by"`
 	Created int64            `json:"created"`
+	// Ban reason and duration in seconds
+	Reason   string `json:"reason,omitempty"`
+	Duration int64  `json:"duration,omitempty"`
+	// Snapshot of the affected post at the time of the action
+	PostBody  string   `json:"postBody,omitempty"`
+	PostFiles []string `json:"postFiles,omitempty"`
+	// All posts affected by bulk actions
+	PostIDs []uint64 `json:"postIDs,omitempty"`
 }
 
 //easyjson:json
 type ModLogRecords []ModLogRecord
 
+// Single open user report about a post
+type Report struct {
+	ID      uint64 `json:"id"`
+	PostID  uint64 `json:"postID"`
+	OP      uint64 `json:"op"`
+	Board   string `json:"board"`
+	Reason  string `json:"reason"`
+	Created int64  `json:"created"`
+}
+
+//easyjson:json
+type Reports []Report
+
+// Post hidden by a moderator, that can still be restored
+type DeletedPost struct {
+	ID        uint64   `json:"id"`
+	OP        uint64   `json:"op"`
+	Board     string   `json:"board"`
+	Time      int64    `json:"time"`
+	Body      string   `json:"body"`
+	Files     []string `json:"files,omitempty"`
+	DeletedAt int64    `json:"deletedAt"`
+	DeletedBy string   `json:"deletedBy"`
+}
+
+//easyjson:json
+type DeletedPosts []DeletedPost
+
+// Appeal of a banned user against the ban
+type BanAppeal struct {
+	ID    uint64 `json:"id"`
+	Board string `json:"board"`
+	// Post the ban was issued for
+	BanID uint64 `json:"banID"`
+	// Reason of the ban
+	Reason   string `json:"reason"`
+	Text     string `json:"text"`
+	Created  int64  `json:"created"`
+	Resolved bool   `json:"resolved"`
+	Accepted bool   `json:"accepted"`
+}
+
+//easyjson:json
+type BanAppeals []BanAppeal
+
+// Notification about a post replying to a post of the account
+type Notification struct {
+	ID uint64 `json:"id"`
+	// Replying post
+	Post  uint64 `json:"post"`
+	OP    uint64 `json:"op"`
+	Board string `json:"board"`
+	// Post of the account, that was replied to
+	Target uint64 `json:"target"`
+	Time   int64  `json:"time"`
+	Read   bool   `json:"read"`
+	// Notified account
+	UserID string `json:"-"`
+}
+
+//easyjson:json
+type Notifications []Notification
+
+// Thread watched by an account
+type WatchedThread struct {
+	ID      uint64 `json:"id"`
+	Board   string `json:"board"`
+	Subject string `json:"subject"`
+	PostCtr uint32 `json:"postCtr"`
+	// Posts since the thread was last viewed
+	Unread uint32 `json:"unread"`
+}
+
+//easyjson:json
+type WatchedThreads []WatchedThread
+
 func (log *ModLogRecords) TryMarshal() []byte {
 	data, err := log.MarshalJSON()
 	if err != nil {
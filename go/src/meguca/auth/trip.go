@@ -0,0 +1,33 @@
+// Secure tripcodes
+
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+)
+
+// Length of tripcodes as stored in the database
+const tripLen = 10
+
+// Installation-wide secret all tripcodes are salted with
+var tripSecret []byte
+
+// SetTripSecret sets the secret used for generating tripcodes
+func SetTripSecret(secret string) {
+	tripSecret = []byte(secret)
+}
+
+// Tripcode computes a secure tripcode from a "name#password" string.
+// Returns an empty string, if there is no password part.
+func Tripcode(name string) string {
+	i := strings.IndexByte(name, '#')
+	if i == -1 || i == len(name)-1 {
+		return ""
+	}
+	mac := hmac.New(sha256.New, tripSecret)
+	mac.Write([]byte(name[i+1:]))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))[:tripLen]
+}
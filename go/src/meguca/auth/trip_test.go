@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"testing"
+
+	. "meguca/test"
+)
+
+func TestTripcode(t *testing.T) {
+	SetTripSecret("secret")
+
+	cases := [...]struct {
+		name, in string
+		empty    bool
+	}{
+		{"no password", "name", true},
+		{"empty password", "name#", true},
+		{"name and password", "name#pass", false},
+		{"password only", "#pass", false},
+	}
+
+	for i := range cases {
+		c := cases[i]
+		t.Run(c.name, func(t *testing.T) {
+			trip := Tripcode(c.in)
+			if (trip == "") != c.empty {
+				t.Fatalf("unexpected tripcode: %q", trip)
+			}
+			if !c.empty && len(trip) != tripLen {
+				t.Fatalf("invalid tripcode length: %d", len(trip))
+			}
+		})
+	}
+
+	AssertDeepEquals(t, Tripcode("a#pass"), Tripcode("b#pass"))
+
+	SetTripSecret("other")
+	if Tripcode("#pass") == Tripcode("#pass2") {
+		t.Fatal("tripcodes collide")
+	}
+}
@@ -139,3 +139,12 @@ func BoardKey(l, b string, page int, catalog bool) Key {
 		Catalog: catalog,
 	}
 }
+
+// ArchiveKey encodes a key for a board archive resource
+func ArchiveKey(l, b string) Key {
+	return Key{
+		Lang:    l,
+		Board:   b,
+		Archive: true,
+	}
+}
@@ -29,6 +29,7 @@ type Key struct {
 	LastN   int
 	Page    int
 	Catalog bool
+	Archive bool
 }
 
 // Single cache entry
@@ -72,6 +73,25 @@ func Clear() {
 	cache = make(map[Key]*list.Element, 10)
 }
 
+// Delete all cached pages of a board. Needed when threads leave the board,
+// which doesn't necessarily change its update counter.
+func DeleteBoard(board string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for k, el := range cache {
+		if k.Board != board {
+			continue
+		}
+		s := ll.Remove(el).(*store)
+		delete(cache, k)
+
+		s.sizeMu.Lock()
+		totalUsed -= s.size
+		s.sizeMu.Unlock()
+	}
+}
+
 // Update the total used memory counter and evict, if over limit
 func updateUsedSize(delta int) {
 	mu.Lock()
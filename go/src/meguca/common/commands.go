@@ -5,7 +5,6 @@ package common
 import (
 	"encoding/json"
 	"fmt"
-	"strconv"
 
 	"github.com/mailru/easyjson/jwriter"
 )
@@ -17,12 +16,50 @@ const (
 	Roll CommandType = iota
 	// Flip coin with X% probability.
 	Flip
+	// Roll N dice with M sides and optional modifier, e.g. 3d6+2.
+	Dice
+	// Answer from the magic 8-ball.
+	EightBall
+	// Countdown to the specified Unix timestamp.
+	Countdown
+	// Random item from the board-defined list.
+	Pick
 )
 
+// Possible magic 8-ball answers
+var EightBallAnswers = []string{
+	"It is certain",
+	"It is decidedly so",
+	"Without a doubt",
+	"Yes definitely",
+	"You may rely on it",
+	"As I see it, yes",
+	"Most likely",
+	"Outlook good",
+	"Yes",
+	"Signs point to yes",
+	"Reply hazy, try again",
+	"Ask again later",
+	"Better not tell you now",
+	"Cannot predict now",
+	"Concentrate and ask again",
+	"Don't count on it",
+	"My reply is no",
+	"My sources say no",
+	"Outlook not so good",
+	"Very doubtful",
+}
+
 type Command struct {
 	Type CommandType
 	Roll int
 	Flip bool
+	// Individual dice results.
+	Dice []int
+	// Countdown target.
+	Countdown int64
+	// 8-ball answer or picked item.
+	Text string
 }
 
 // Dynamically marshal the appropriate fields by struct type.
@@ -36,6 +73,21 @@ func (c Command) MarshalEasyJSON(w *jwriter.Writer) {
 		w.Int(c.Roll)
 	case Flip:
 		w.Bool(c.Flip)
+	case Dice:
+		w.RawByte('[')
+		for i, v := range c.Dice {
+			if i != 0 {
+				w.RawByte(',')
+			}
+			w.Int(v)
+		}
+		w.RawByte(']')
+	case Countdown:
+		w.Int64(c.Countdown)
+	case EightBall, Pick:
+		w.String(c.Text)
+	default:
+		w.RawString("null")
 	}
 
 	w.RawByte('}')
@@ -50,25 +102,32 @@ func (c Command) MarshalJSON() ([]byte, error) {
 // Decode a dynamically-typed JSON-encoded command into the
 // statically-typed Command struct.
 func (c *Command) UnmarshalJSON(data []byte) error {
-	if len(data) < 18 {
-		return fmt.Errorf("data too short: %s", string(data))
+	var raw struct {
+		Type *CommandType    `json:"type"`
+		Val  json.RawMessage `json:"val"`
 	}
-
-	typ, err := strconv.ParseUint(string(data[8]), 10, 8)
+	err := json.Unmarshal(data, &raw)
 	if err != nil {
 		return err
 	}
+	if raw.Type == nil || raw.Val == nil {
+		return fmt.Errorf("invalid command: %s", string(data))
+	}
 
-	data = data[16 : len(data)-1]
-	switch CommandType(typ) {
+	*c = Command{Type: *raw.Type}
+	switch c.Type {
 	case Roll:
-		c.Type = Roll
-		err = json.Unmarshal(data, &c.Roll)
+		err = json.Unmarshal(raw.Val, &c.Roll)
 	case Flip:
-		c.Type = Flip
-		err = json.Unmarshal(data, &c.Flip)
+		err = json.Unmarshal(raw.Val, &c.Flip)
+	case Dice:
+		err = json.Unmarshal(raw.Val, &c.Dice)
+	case Countdown:
+		err = json.Unmarshal(raw.Val, &c.Countdown)
+	case EightBall, Pick:
+		err = json.Unmarshal(raw.Val, &c.Text)
 	default:
-		return fmt.Errorf("unknown command type: %d", typ)
+		return fmt.Errorf("unknown command type: %d", c.Type)
 	}
 	return err
 }
@@ -0,0 +1,61 @@
+package common
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "meguca/test"
+)
+
+func TestCommandJSON(t *testing.T) {
+	t.Parallel()
+
+	cases := [...]struct {
+		name string
+		cmd  Command
+		json string
+	}{
+		{"roll", Command{Type: Roll, Roll: 42}, `{"type":0,"val":42}`},
+		{"flip", Command{Type: Flip, Flip: true}, `{"type":1,"val":true}`},
+		{"dice", Command{Type: Dice, Dice: []int{4, 1, 6}}, `{"type":2,"val":[4,1,6]}`},
+		{"8ball", Command{Type: EightBall, Text: "Yes"}, `{"type":3,"val":"Yes"}`},
+		{"countdown", Command{Type: Countdown, Countdown: 1700000000}, `{"type":4,"val":1700000000}`},
+		{"pick", Command{Type: Pick, Text: "\"tea\""}, `{"type":5,"val":"\"tea\""}`},
+	}
+
+	for i := range cases {
+		c := cases[i]
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf, err := json.Marshal(c.cmd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			AssertDeepEquals(t, string(buf), c.json)
+
+			var dec Command
+			err = json.Unmarshal(buf, &dec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			AssertDeepEquals(t, dec, c.cmd)
+		})
+	}
+}
+
+func TestCommandJSONReordered(t *testing.T) {
+	t.Parallel()
+
+	var c Command
+	err := json.Unmarshal([]byte(`{ "val": 7, "type": 0 }`), &c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertDeepEquals(t, c, Command{Type: Roll, Roll: 7})
+
+	err = json.Unmarshal([]byte(`{"type":99,"val":1}`), &c)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
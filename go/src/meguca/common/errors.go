@@ -14,6 +14,8 @@ var (
 	ErrBodyTooLong    = ErrTooLong("Post body")
 	ErrInvalidCreds   = errors.New("Invalid login credentials")
 	ErrContainsNull   = errors.New("Null byte in non-concatenated message")
+	ErrInvalidPoll    = errors.New("Invalid poll")
+	ErrPollTooLong    = ErrTooLong("Poll question or option")
 )
 
 // ErrTooLong is passed, when a field exceeds the maximum string length for
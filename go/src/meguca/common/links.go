@@ -0,0 +1,61 @@
+// Post links routines.
+
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mailru/easyjson/jwriter"
+)
+
+// Link to another post, possibly on another board.
+type Link struct {
+	ID    uint64
+	OP    uint64
+	Board string
+}
+
+// Encode as a compact [id, op, "board"] tuple.
+func (l Link) MarshalEasyJSON(w *jwriter.Writer) {
+	w.RawByte('[')
+	w.Uint64(l.ID)
+	w.RawByte(',')
+	w.Uint64(l.OP)
+	w.RawByte(',')
+	w.String(l.Board)
+	w.RawByte(']')
+}
+
+func (l Link) MarshalJSON() ([]byte, error) {
+	var w jwriter.Writer
+	l.MarshalEasyJSON(&w)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// Decode a link tuple. Links stored before boards were recorded consist
+// only of [id, op].
+func (l *Link) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+	if len(raw) != 2 && len(raw) != 3 {
+		return fmt.Errorf("invalid link: %s", string(data))
+	}
+
+	*l = Link{}
+	err = json.Unmarshal(raw[0], &l.ID)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(raw[1], &l.OP)
+	if err != nil {
+		return err
+	}
+	if len(raw) == 3 {
+		err = json.Unmarshal(raw[2], &l.Board)
+	}
+	return err
+}
@@ -0,0 +1,42 @@
+package common
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "meguca/test"
+)
+
+func TestLinkJSON(t *testing.T) {
+	t.Parallel()
+
+	l := Link{ID: 22, OP: 21, Board: "a"}
+	buf, err := json.Marshal(Links{l})
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertDeepEquals(t, string(buf), `[[22,21,"a"]]`)
+
+	var dec Links
+	err = json.Unmarshal(buf, &dec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertDeepEquals(t, dec, Links{l})
+}
+
+func TestLinkJSONWithoutBoard(t *testing.T) {
+	t.Parallel()
+
+	var l Link
+	err := json.Unmarshal([]byte(`[22,21]`), &l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertDeepEquals(t, l, Link{ID: 22, OP: 21})
+
+	err = json.Unmarshal([]byte(`[22]`), &l)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -32,16 +32,34 @@ func (b Board) Less(i, j int) bool {
 type Thread struct {
 	Abbrev    bool   `json:"abbrev,omitempty"`
 	Sticky    bool   `json:"sticky,omitempty"`
+	Archived  bool   `json:"archived,omitempty"`
+	Locked    bool   `json:"locked,omitempty"`
 	PostCtr   uint32 `json:"postCtr"`
 	ImageCtr  uint32 `json:"imageCtr"`
 	ReplyTime int64  `json:"replyTime"`
 	BumpTime  int64  `json:"bumpTime"`
 	Subject   string `json:"subject"`
 	Board     string `json:"board"`
+	Poll      *Poll  `json:"poll,omitempty"`
 	*Post
 	Posts Posts `json:"posts"`
 }
 
+// Poll attached to a thread by its OP. Votes contains the current tally
+// for each option in the same order as Options.
+type Poll struct {
+	Question string   `json:"question"`
+	Options  []string `json:"options"`
+	Multiple bool     `json:"multiple,omitempty"`
+	Expires  int64    `json:"expires,omitempty"`
+	Votes    []uint64 `json:"votes"`
+}
+
+// Expired returns, if the poll no longer accepts votes
+func (p *Poll) Expired() bool {
+	return p.Expires != 0 && time.Now().Unix() >= p.Expires
+}
+
 // Post is a generic post exposed publically through the JSON API.
 type Post struct {
 	ID        uint64   `json:"id"`
@@ -52,8 +70,11 @@ type Post struct {
 	Settings  string   `json:"settings,omitempty"`
 	UserColor string   `json:"userColor,omitempty"`
 	ShowColor bool     `json:"ShowColor,omitempty"`
+	Sage      bool     `json:"sage,omitempty"`
+	Trip      string   `json:"trip,omitempty"`
 	Body      string   `json:"body"`
 	Links     Links    `json:"links,omitempty"`
+	Backlinks Links    `json:"backlinks,omitempty"`
 	Commands  Commands `json:"commands,omitempty"`
 	Files     Files    `json:"files,omitempty"`
 	Reacts    Reacts   `json:"reacts"`
@@ -68,11 +89,18 @@ type StandalonePost struct {
 	Board string `json:"board"`
 }
 
+// SearchResult is a post matched by the full-text search along with the
+// subject of its thread
+type SearchResult struct {
+	StandalonePost
+	Subject string `json:"subject"`
+}
+
 // Posts.
 type Posts []*Post
 
 // Post links.
-type Links [][2]uint64
+type Links []Link
 
 // Post commands.
 type Commands []Command
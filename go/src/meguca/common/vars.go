@@ -15,6 +15,19 @@ const (
 	MaxLenIgnoreList   = 100
 	MaxLenStaffList    = 1000
 	MaxLenBansList     = 1000
+	MaxLenPollQuestion = 200
+	MaxLenPollOption   = 100
+	MaxPollOptions     = 10
+	MaxCommands        = 10
+	MaxPickItems       = 100
+	MaxLenPickItem     = 100
+	MaxLenSearchQuery  = 200
+	MaxLenReportReason = 100
+	MaxLenAppeal       = 1000
+	MaxWordFilters     = 100
+	MaxLenWordFilter   = 200
+	// Days
+	MaxPollDuration = 30
 )
 
 // Various cryptographic token exact lengths
@@ -33,6 +46,15 @@ const (
 	ThreadsPerPage       = 20
 	NumPostsAtIndex      = 3
 	NumPostsOnRequest    = 100
+	SearchResultsPerPage = 50
+	NotificationsPerPage = 100
+	DeletedPostsPerPage  = 100
+	DefaultMaxThreads    = 200
+	// Seconds after post creation, when its author can still edit it
+	DefaultEditWindow = 5 * 60
+	// Reports a single IP can send per ReportInterval minutes
+	MaxReportsPerInterval = 10
+	ReportInterval        = 60
 )
 
 // Available themes. Change this, when adding any new ones.
@@ -25,6 +25,8 @@ const (
 	MessageBanned
 	MessageDeleteImage
 	MessageReacted
+	MessageEditPost
+	MessageUndeletePost
 )
 
 // >= 30 are miscellaneous and do not write to post models
@@ -58,6 +60,27 @@ const (
 	// Notify the client, he needs a captcha solved
 	MessageCaptcha
 	SmilesUpdated
+
+	// Thread was moved to the archive and is read-only now
+	MessageThreadArchived
+
+	// Thread was locked or unlocked by a moderator
+	MessageThreadLocked
+
+	// Updated vote tallies of a thread poll
+	MessagePollUpdated
+
+	// Post in another thread linked an existing post
+	MessageBacklink
+
+	// Post counter of a watched thread changed
+	MessageWatchedThread
+
+	// Redirect the client to a specific thread
+	MessageRedirectThread
+
+	// Reply to a post of the client's account
+	MessageReplyNotification
 )
 
 // Forwarded functions from "meguca/feeds" to avoid circular imports
@@ -80,11 +103,23 @@ var (
 	// Propagate a message about a post being deleted
 	DeletePost func(id, op uint64) error
 
+	// Propagate a message about a deleted post being restored
+	UndeletePost func(id, op uint64) error
+
 	// Propagate a message about an image being deleted from a post
 	DeleteImage func(id, op uint64) error
 
 	// Propagate a message about an image being spoilered
 	SpoilerImage func(id, op uint64) error
+
+	// Propagate a message about a thread being archived
+	ArchiveThread func(op uint64) error
+
+	// Propagate a message about a thread being locked or unlocked
+	LockThread func(op uint64, locked bool) error
+
+	// Redirect all clients synced to a thread to another thread
+	RedirectThread func(op uint64, board string, id uint64)
 )
 
 // Client exposes some globally accessible websocket client functionality
@@ -92,7 +127,9 @@ var (
 type Client interface {
 	Send([]byte)
 	Redirect(board string)
+	RedirectThread(board string, id uint64)
 	IP() string
+	UserID() string
 	Close(error)
 }
 
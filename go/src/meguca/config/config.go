@@ -30,6 +30,15 @@ var (
 			MaxFiles:   common.DefaultMaxFiles,
 			DefaultCSS: common.DefaultCSS,
 		},
+		Antispam: Antispam{
+			MaxScore:        600,
+			Window:          10,
+			MinDuplicateLen: 20,
+			DuplicateScore:  30,
+			FileScore:       20,
+			NewIPPeriod:     24,
+			MaxNewIPLinks:   2,
+		},
 	}
 )
 
@@ -126,6 +135,9 @@ func SetBoardConfig(conf BoardConfig) (err error) {
 	if err != nil {
 		return
 	}
+	if err = compileWordFilters(&conf); err != nil {
+		return
+	}
 	boardMu.Lock()
 	defer boardMu.Unlock()
 	conf.json = data
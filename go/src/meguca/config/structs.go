@@ -2,8 +2,36 @@
 
 package config
 
+import "regexp"
+
+//easyjson:json
 type ServerConfig struct {
 	ServerPublic
+	// Days to keep moderation log entries for. Zero keeps them forever.
+	ModLogRetention int      `json:"modLogRetention"`
+	Antispam        Antispam `json:"antispam"`
+}
+
+// Anti-spam thresholds. Scores are measured in seconds and decay in real
+// time.
+type Antispam struct {
+	// Score, after which posting requires a captcha. Zero, the default,
+	// disables spam detection.
+	CaptchaScore int `json:"captchaScore"`
+	// Score, after which posts are rejected even with a captcha
+	MaxScore int `json:"maxScore"`
+	// Minutes to look back for duplicate bodies and files
+	Window int `json:"window"`
+	// Shorter bodies are never considered duplicates
+	MinDuplicateLen int `json:"minDuplicateLen"`
+	// Score for each identical body posted in another thread
+	DuplicateScore int `json:"duplicateScore"`
+	// Score for each recent post with the same file
+	FileScore int `json:"fileScore"`
+	// Hours after its first post, during which an IP is considered new
+	NewIPPeriod int `json:"newIPPeriod"`
+	// Maximum number of links in a post by a new IP
+	MaxNewIPLinks int `json:"maxNewIPLinks"`
 }
 
 //easyjson:json
@@ -30,10 +58,46 @@ type BoardConfig struct {
 	ModOnly     bool       `json:"modOnly,omitempty"`
 	AccessMode  AccessMode `json:"accessMode,omitempty"`
 	IncludeAnon bool       `json:"includeAnon,omitempty"`
+	// Maximum number of live threads, older ones are archived.
+	MaxThreads int `json:"maxThreads,omitempty"`
+	// Number of posts after which a thread stops bumping.
+	BumpLimit int `json:"bumpLimit,omitempty"`
+	// Seconds after creation, when a post can still be edited.
+	EditWindow int `json:"editWindow,omitempty"`
+	// Ignore tripcode passwords in post names.
+	DisableTrips bool `json:"disableTrips,omitempty"`
+	// Items for the !pick post command.
+	PickItems []string `json:"pickItems,omitempty"`
+	// Phrases replaced in or blocking new posts.
+	WordFilters []WordFilter `json:"wordFilters,omitempty"`
 	// Pregenerated public JSON.
 	json []byte
 }
 
+// WordFilterMode specifies, what happens to posts matching a word filter.
+// Stricter modes have higher values.
+type WordFilterMode int
+
+const (
+	// Replace matched text with the filter's replacement
+	FilterReplace WordFilterMode = iota
+	// Hide the post and add it to the board's report queue, until a
+	// moderator approves it. Threads can't be held and are refused instead.
+	FilterHold
+	// Refuse to create the post
+	FilterReject
+)
+
+type WordFilter struct {
+	Pattern string `json:"pattern"`
+	// Treat Pattern as a regular expression instead of plain text.
+	Regex       bool           `json:"regex,omitempty"`
+	Replacement string         `json:"replacement,omitempty"`
+	Mode        WordFilterMode `json:"mode,omitempty"`
+	// Compiled by SetBoardConfig.
+	re *regexp.Regexp
+}
+
 //easyjson:json
 type BoardPublic struct {
 	ID       string `json:"id"`
@@ -0,0 +1,83 @@
+// Per-board word filters
+
+package config
+
+import (
+	"errors"
+	"regexp"
+	"unicode/utf8"
+
+	"meguca/common"
+)
+
+var ErrInvalidWordFilter = errors.New("invalid word filter")
+
+// Compile validates the filter and prepares it for matching. Plain text
+// patterns are matched case-insensitively.
+func (f *WordFilter) Compile() (err error) {
+	switch {
+	case f.Pattern == "",
+		len(f.Pattern) > common.MaxLenWordFilter,
+		len(f.Replacement) > common.MaxLenWordFilter,
+		f.Mode < FilterReplace,
+		f.Mode > FilterReject:
+		return ErrInvalidWordFilter
+	}
+	if f.Regex {
+		f.re, err = regexp.Compile(f.Pattern)
+	} else {
+		f.re, err = regexp.Compile("(?i)" + regexp.QuoteMeta(f.Pattern))
+	}
+	return
+}
+
+// HoldReason returns the report reason for posts held by the filter
+func (f *WordFilter) HoldReason() string {
+	reason := "Word filter: " + f.Pattern
+	if utf8.RuneCountInString(reason) > common.MaxLenReportReason {
+		reason = string([]rune(reason)[:common.MaxLenReportReason])
+	}
+	return reason
+}
+
+// Compile filters into a copy of the slice, so the caller's configuration
+// is not modified.
+func compileWordFilters(conf *BoardConfig) error {
+	if len(conf.WordFilters) == 0 {
+		return nil
+	}
+	filters := make([]WordFilter, len(conf.WordFilters))
+	copy(filters, conf.WordFilters)
+	for i := range filters {
+		if err := filters[i].Compile(); err != nil {
+			return err
+		}
+	}
+	conf.WordFilters = filters
+	return nil
+}
+
+// FilterWords applies the word filters of a board to text. Replacing
+// filters are applied in order. Of all other matching filters the
+// strictest one is returned, if any.
+func FilterWords(board, text string) (res string, hit *WordFilter) {
+	res = text
+	filters := GetBoardConfig(board).WordFilters
+	for i := range filters {
+		f := &filters[i]
+		if f.re == nil || !f.re.MatchString(res) {
+			continue
+		}
+		switch {
+		case f.Mode != FilterReplace:
+			if hit == nil || f.Mode > hit.Mode {
+				hit = f
+			}
+		case f.Regex:
+			res = f.re.ReplaceAllString(res, f.Replacement)
+		default:
+			res = f.re.ReplaceAllLiteralString(res, f.Replacement)
+		}
+	}
+	return
+}
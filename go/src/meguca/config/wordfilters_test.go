@@ -0,0 +1,169 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"meguca/common"
+)
+
+func TestCompileWordFilter(t *testing.T) {
+	long := strings.Repeat("a", common.MaxLenWordFilter+1)
+
+	cases := [...]struct {
+		name  string
+		f     WordFilter
+		valid bool
+	}{
+		{"plain text", WordFilter{Pattern: "foo"}, true},
+		{"regex", WordFilter{Pattern: `fo+\b`, Regex: true}, true},
+		{"regex metacharacters in plain text", WordFilter{Pattern: "a(b"}, true},
+		{"hold", WordFilter{Pattern: "foo", Mode: FilterHold}, true},
+		{"reject", WordFilter{Pattern: "foo", Mode: FilterReject}, true},
+		{"empty pattern", WordFilter{}, false},
+		{"pattern too long", WordFilter{Pattern: long}, false},
+		{
+			"replacement too long",
+			WordFilter{Pattern: "foo", Replacement: long},
+			false,
+		},
+		{"negative mode", WordFilter{Pattern: "foo", Mode: -1}, false},
+		{"unknown mode", WordFilter{Pattern: "foo", Mode: FilterReject + 1}, false},
+		{"invalid regex", WordFilter{Pattern: "a(b", Regex: true}, false},
+	}
+
+	for i := range cases {
+		c := cases[i]
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			err := c.f.Compile()
+			if (err == nil) != c.valid {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if c.valid && c.f.re == nil {
+				t.Fatal("filter not compiled")
+			}
+		})
+	}
+}
+
+func TestFilterWords(t *testing.T) {
+	err := SetBoardConfig(BoardConfig{
+		BoardPublic: BoardPublic{ID: "a"},
+		WordFilters: []WordFilter{
+			{Pattern: "foo", Replacement: "bar"},
+			{Pattern: `(\d+) apples`, Regex: true, Replacement: "$1 pears"},
+			{Pattern: "$1", Replacement: "$2"},
+			{Pattern: "held", Mode: FilterHold},
+			{Pattern: "rejected", Mode: FilterReject},
+			{Pattern: "bar baz", Mode: FilterHold},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer RemoveBoard("a")
+
+	cases := [...]struct {
+		name, board, in, out string
+		mode                 WordFilterMode
+		hit                  bool
+	}{
+		{
+			name: "no match",
+			in:   "nothing to see",
+			out:  "nothing to see",
+		},
+		{
+			name: "case-insensitive replacement",
+			in:   "Foo and FOO",
+			out:  "bar and bar",
+		},
+		{
+			name: "regex replacement",
+			in:   "3 apples",
+			out:  "3 pears",
+		},
+		{
+			name: "literal replacement",
+			in:   "costs $1",
+			out:  "costs $2",
+		},
+		{
+			name: "hold",
+			in:   "held post",
+			out:  "held post",
+			mode: FilterHold,
+			hit:  true,
+		},
+		{
+			name: "strictest filter wins",
+			in:   "held and rejected",
+			out:  "held and rejected",
+			mode: FilterReject,
+			hit:  true,
+		},
+		{
+			name: "match after replacement",
+			in:   "foo baz",
+			out:  "bar baz",
+			mode: FilterHold,
+			hit:  true,
+		},
+		{
+			name:  "other board",
+			board: "b",
+			in:    "foo rejected",
+			out:   "foo rejected",
+		},
+	}
+
+	for i := range cases {
+		c := cases[i]
+		t.Run(c.name, func(t *testing.T) {
+			board := c.board
+			if board == "" {
+				board = "a"
+			}
+			out, hit := FilterWords(board, c.in)
+			if out != c.out {
+				t.Fatalf("expected %q, got %q", c.out, out)
+			}
+			switch {
+			case (hit != nil) != c.hit:
+				t.Fatalf("unexpected hit: %v", hit)
+			case hit != nil && hit.Mode != c.mode:
+				t.Fatalf("expected mode %d, got %d", c.mode, hit.Mode)
+			}
+		})
+	}
+}
+
+func TestHoldReason(t *testing.T) {
+	cases := [...]struct {
+		name, pattern, out string
+	}{
+		{"short", "foo", "Word filter: foo"},
+		{
+			"truncated by runes",
+			strings.Repeat("ж", common.MaxLenWordFilter),
+			"Word filter: " + strings.Repeat("ж", common.MaxLenReportReason-13),
+		},
+	}
+
+	for i := range cases {
+		c := cases[i]
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			f := WordFilter{Pattern: c.pattern}
+			s := f.HoldReason()
+			if s != c.out {
+				t.Fatalf("expected %q, got %q", c.out, s)
+			}
+			if !utf8.ValidString(s) {
+				t.Fatal("invalid UTF-8")
+			}
+		})
+	}
+}
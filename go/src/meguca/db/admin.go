@@ -2,6 +2,7 @@ package db
 
 import (
 	"database/sql"
+	"sort"
 	"time"
 
 	"meguca/auth"
@@ -28,80 +29,149 @@ func GetIP(id uint64) (string, error) {
 	return ip.String, err
 }
 
-// Ban IPs from accessing a specific board. Need to target posts. Returns all
-// banned IPs.
-func Ban(board, reason, by string, expires time.Time, ids ...uint64) (
+// Ban IPs from accessing a specific board. Need to target posts. opts
+// specifies, if the IPs, their networks or unique IDs are banned. Returns all
+// IPs of the targeted posts.
+func Ban(
+	board, reason, by string,
+	expires time.Time,
+	opts auth.BanOptions,
+	ids ...uint64,
+) (
 	ips map[string]uint64, err error,
 ) {
-	type post struct {
-		id, op       uint64
-		uniqueID, ip string
+	posts, err := ban(board, reason, by, expires, opts, ids)
+	if err != nil {
+		return
 	}
+	return propagateBans(posts)
+}
 
+func ban(
+	board, reason, by string,
+	expires time.Time,
+	opts auth.BanOptions,
+	ids []uint64,
+) (
+	posts []bannedPost, err error,
+) {
+	tx, err := BeginTx()
+	if err != nil {
+		return
+	}
+	defer EndTx(tx, &err)
+	return writeBans(tx, board, reason, by, expires, opts, ids)
+}
+
+// Post targeted by a ban
+type bannedPost struct {
+	id, op       uint64
+	uniqueID, ip string
+}
+
+// Write bans of the authors of the passed posts inside a transaction. The
+// bans need to be propagated with propagateBans after the commit.
+func writeBans(
+	tx *sql.Tx,
+	board, reason, by string,
+	expires time.Time,
+	opts auth.BanOptions,
+	ids []uint64,
+) (
+	posts []bannedPost, err error,
+) {
 	// Retrieve matching posts
-	ips = make(map[string]uint64, len(ids))
-	posts := make([]post, 0, len(ids))
+	posts = make([]bannedPost, 0, len(ids))
 	for _, id := range ids {
-		ip, err := GetIP(id)
+		var ip sql.NullString
+		err = getStatement(tx, "get_ip").QueryRow(id).Scan(&ip)
 		switch err {
 		case nil:
 		case sql.ErrNoRows:
+			err = nil
 			continue
 		default:
-			return nil, err
+			return
 		}
-		ips[ip] = id
-		posts = append(posts, post{id: id, ip: ip})
-	}
-
-	if len(ips) == 0 {
-		return
-	}
-
-	// Retrieve their UniqueIDs
-	for i, post := range posts {
-		var uniqueID string
-		uniqueID, err = GetUniqueID(post.id)
-		post.uniqueID = uniqueID
-		posts[i] = post
+		posts = append(posts, bannedPost{id: id, ip: ip.String})
 	}
 
-	// Retrieve their OPs
-	for i, post := range posts {
-		post.op, err = GetPostOP(post.id)
+	// Retrieve their UniqueIDs and OPs
+	for i := range posts {
+		post := &posts[i]
+		var uniqueID sql.NullString
+		err = getStatement(tx, "get_unique_id").
+			QueryRow(post.id).
+			Scan(&uniqueID)
+		if err != nil {
+			return
+		}
+		post.uniqueID = uniqueID.String
+		err = getStatement(tx, "get_post_op").
+			QueryRow(post.id).
+			Scan(&post.op)
 		if err != nil {
 			return
 		}
-		posts[i] = post
 	}
 
 	// Write ban messages to posts
 	for _, post := range posts {
-		err = execPrepared("ban_post", post.id)
+		err = execPreparedTx(tx, "ban_post", post.id)
 		if err != nil {
 			return
 		}
-		if !IsTest {
-			err = common.BanPost(post.id, post.op)
-			if err != nil {
-				return
-			}
-		}
 	}
 
 	// Write bans to the ban table
 	for _, post := range posts {
-		err = execPrepared("write_ban", board, post.ip, post.id,
-			by, expires, reason, post.uniqueID)
+		var target string
+		target, err = opts.Target(post.ip)
+		if err != nil {
+			return
+		}
+		// Posts older than 30 days have no unique ID to ban
+		scope := opts.Scope
+		if scope == auth.BanUniqueID && post.uniqueID == "" {
+			scope = auth.BanIP
+		}
+		err = execPreparedTx(tx, "write_ban", board, target, post.id,
+			by, expires, reason, post.uniqueID, scope, banDuration(expires))
 		if err != nil {
 			return
 		}
 	}
+	return
+}
 
+// Notify clients and servers of committed bans. Returns the banned IPs mapped
+// to the posts they were banned for.
+func propagateBans(posts []bannedPost) (ips map[string]uint64, err error) {
+	ips = make(map[string]uint64, len(posts))
+	for _, post := range posts {
+		ips[post.ip] = post.id
+	}
+	if len(posts) == 0 {
+		return
+	}
+
+	if !IsTest {
+		for _, post := range posts {
+			err = common.BanPost(post.id, post.op)
+			if err != nil {
+				return
+			}
+		}
+	}
 	_, err = db.Exec(`notify bans_updated`)
 	return
 }
 
+// Seconds left until a ban expires, for the moderation log
+func banDuration(expires time.Time) int64 {
+	return int64(time.Until(expires) / time.Second)
+}
+
 // Unban lifts a ban from a specific post on a specific board
 func Unban(board string, id uint64, by string) error {
 	return execPrepared("unban", board, id, by)
@@ -119,7 +189,7 @@ func loadBans() error {
 // RefreshBanCache loads up to date bans from the database and caches them in
 // memory
 func RefreshBanCache() (err error) {
-	r, err := db.Query(`SELECT ip, board FROM bans`)
+	r, err := prepared["load_bans"].Query()
 	if err != nil {
 		return
 	}
@@ -127,11 +197,15 @@ func RefreshBanCache() (err error) {
 
 	bans := make([]auth.Ban, 0, 16)
 	for r.Next() {
-		var b auth.Ban
-		err = r.Scan(&b.IP, &b.Board)
+		var (
+			b        auth.Ban
+			uniqueID sql.NullString
+		)
+		err = r.Scan(&b.IP, &b.Board, &b.Scope, &uniqueID)
 		if err != nil {
 			return
 		}
+		b.UniqueID = uniqueID.String
 		bans = append(bans, b)
 	}
 	err = r.Err()
@@ -155,12 +229,8 @@ func moderatePost(
 		return
 	}
 
-	if id == op && query == "delete_post" {
-		query = "delete_thread"
-	}
-
 	err = execPrepared(query, id, by)
-	if err != nil {
+	if err != nil || IsTest {
 		return
 	}
 
@@ -170,7 +240,234 @@ func moderatePost(
 
 // DeletePost deletes post
 func DeletePost(id uint64, by string) error {
-	return moderatePost(id, by, "delete_post", common.DeletePost)
+	return deletePost(id, by, auth.DeletePost)
+}
+
+// UndeletePost restores a post deleted by a moderator. Returns sql.ErrNoRows,
+// if the post is not deleted.
+func UndeletePost(id uint64, by string) error {
+	return restorePost("undelete_post", id, by)
+}
+
+// Restore a hidden post with the passed query and propagate it to clients
+func restorePost(queryID string, id uint64, by string) (err error) {
+	op, err := GetPostOP(id)
+	if err != nil {
+		return
+	}
+	res, err := prepared[queryID].Exec(id, by)
+	if err != nil {
+		return
+	}
+	n, err := res.RowsAffected()
+	switch {
+	case err != nil:
+		return
+	case n == 0:
+		return sql.ErrNoRows
+	case IsTest:
+		return
+	}
+	return common.UndeletePost(id, op)
+}
+
+// NukeBan is a ban of the author issued together with nuking their posts
+type NukeBan struct {
+	Reason  string
+	Expires time.Time
+	Options auth.BanOptions
+}
+
+// NukePosts deletes all posts on a board by the author of the target post,
+// matched by IP or unique ID. Threads of matched OPs are deleted completely.
+// If since is not zero, only posts created since that Unix time are deleted.
+// If ban is not nil, the author is banned in the same transaction.
+// Instead of an entry per post a single moderation log entry references all
+// deleted posts, including replies deleted with their threads. Returns the
+// IDs of deleted posts and the banned IPs.
+func NukePosts(
+	id uint64,
+	board string,
+	byUniqueID bool,
+	since int64,
+	by string,
+	ban *NukeBan,
+) (
+	ids []uint64, ips map[string]uint64, err error,
+) {
+	ops, banned, err := nukePosts(id, board, byUniqueID, since, by, ban)
+	if err != nil {
+		return
+	}
+	ids = make([]uint64, 0, len(ops))
+	for post := range ops {
+		ids = append(ids, post)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	if ban != nil {
+		ips, err = propagateBans(banned)
+		if err != nil {
+			return
+		}
+	}
+	if IsTest {
+		return
+	}
+	for _, post := range ids {
+		err = common.DeletePost(post, ops[post])
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Delete matched posts in one transaction and return them mapped to
+// their OPs
+func nukePosts(
+	id uint64,
+	board string,
+	byUniqueID bool,
+	since int64,
+	by string,
+	ban *NukeBan,
+) (
+	ops map[uint64]uint64, banned []bannedPost, err error,
+) {
+	tx, err := BeginTx()
+	if err != nil {
+		return
+	}
+	defer EndTx(tx, &err)
+
+	if ban != nil {
+		banned, err = writeBans(tx, board, ban.Reason, by, ban.Expires,
+			ban.Options, []uint64{id})
+		if err != nil {
+			return
+		}
+	}
+
+	rs, err := getStatement(tx, "get_poster_posts").
+		Query(id, board, byUniqueID, since)
+	if err != nil {
+		return
+	}
+	defer rs.Close()
+	matched := make(map[uint64]uint64, 16)
+	for rs.Next() {
+		var post, op uint64
+		err = rs.Scan(&post, &op)
+		if err != nil {
+			return
+		}
+		matched[post] = op
+	}
+	err = rs.Err()
+	if err != nil {
+		return
+	}
+	rs.Close()
+
+	ops = make(map[uint64]uint64, len(matched))
+	for post, op := range matched {
+		if post != op {
+			err = execPreparedTx(tx, "nuke_post", post, by)
+			if err != nil {
+				return
+			}
+			ops[post] = op
+			continue
+		}
+
+		// Replies by other posters are deleted together with the thread
+		var r *sql.Rows
+		r, err = getStatement(tx, "nuke_thread").Query(post)
+		if err != nil {
+			return
+		}
+		for r.Next() {
+			var reply uint64
+			err = r.Scan(&reply)
+			if err != nil {
+				r.Close()
+				return
+			}
+			ops[reply] = op
+		}
+		err = r.Err()
+		r.Close()
+		if err != nil {
+			return
+		}
+	}
+
+	ids := make([]int64, 0, len(ops))
+	for post := range ops {
+		ids = append(ids, int64(post))
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	err = execPreparedTx(tx, "log_nuke", board, id, by, pq.Array(ids))
+	return
+}
+
+// GetDeletedPosts retrieves the most recently deleted posts of a board
+func GetDeletedPosts(board string) (posts auth.DeletedPosts, err error) {
+	posts = make(auth.DeletedPosts, 0)
+	rs, err := prepared["get_deleted_posts"].
+		Query(board, common.DeletedPostsPerPage)
+	if err != nil {
+		return
+	}
+	defer rs.Close()
+	for rs.Next() {
+		var (
+			p     auth.DeletedPost
+			files pq.StringArray
+		)
+		err = rs.Scan(&p.ID, &p.OP, &p.Board, &p.Time, &p.Body, &p.DeletedAt,
+			&p.DeletedBy, &files)
+		if err != nil {
+			return
+		}
+		p.Files = []string(files)
+		posts = append(posts, p)
+	}
+	err = rs.Err()
+	return
+}
+
+// SelfDeletePost deletes a post on its author's request. The post is deleted
+// the same way as by moderators, but logged as a distinct action.
+func SelfDeletePost(id uint64, by string) error {
+	return deletePost(id, by, auth.SelfDeletePost)
+}
+
+// Delete a post or the entire thread of an OP. Post deletions are logged as
+// the passed moderation action.
+func deletePost(id uint64, by string, action auth.ModerationAction) (
+	err error,
+) {
+	op, err := GetPostOP(id)
+	if err != nil {
+		return
+	}
+
+	if id == op {
+		err = execPrepared("delete_thread", id, by)
+	} else {
+		err = execPrepared("delete_post", id, by, action)
+	}
+	if err != nil || IsTest {
+		return
+	}
+
+	err = common.DeletePost(id, op)
+	return
 }
 
 // GetSameIPPosts returns posts with the same IP and on the same board as the
@@ -216,9 +513,113 @@ func GetSameIPPosts(id uint64, board string) (
 	return
 }
 
-// SetThreadSticky sets the sticky field on a thread
-func SetThreadSticky(id uint64, sticky bool) error {
-	return execPrepared("set_sticky", id, sticky)
+// SetThreadSticky sets the sticky field on a thread. Returns sql.ErrNoRows,
+// if the thread does not exist.
+func SetThreadSticky(id uint64, sticky bool, by string) error {
+	return setThreadFlag("set_sticky", id, sticky, by)
+}
+
+// SetThreadLocked sets the locked field on a thread and notifies its feed.
+// Returns sql.ErrNoRows, if the thread does not exist.
+func SetThreadLocked(id uint64, locked bool, by string) (err error) {
+	err = setThreadFlag("set_locked", id, locked, by)
+	if err != nil || IsTest {
+		return
+	}
+	return common.LockThread(id, locked)
+}
+
+func setThreadFlag(queryID string, id uint64, val bool, by string) error {
+	res, err := prepared[queryID].Exec(id, val, by)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	switch {
+	case err != nil:
+		return err
+	case n == 0:
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// MoveThread moves a thread with all of its posts to another board and
+// redirects clients of its feed there
+func MoveThread(id uint64, from, to, by string) (err error) {
+	err = moveThread(id, from, to, by)
+	if err != nil || IsTest {
+		return
+	}
+	common.RedirectThread(id, to, id)
+	return
+}
+
+func moveThread(id uint64, from, to, by string) (err error) {
+	tx, err := BeginTx()
+	if err != nil {
+		return
+	}
+	defer EndTx(tx, &err)
+
+	err = execPreparedTx(tx, "move_thread", id, to, by, from)
+	if err != nil {
+		return
+	}
+	err = execPreparedTx(tx, "move_thread_posts", id, to)
+	if err != nil {
+		return
+	}
+	err = execPreparedTx(tx, "relink_thread_posts", id)
+	return
+}
+
+// MergeThread moves all posts of thread src into thread dst, which is
+// located on board, and deletes src. Returns the IDs of the moved posts.
+func MergeThread(src, dst uint64, board, by string) (posts []uint64, err error) {
+	posts, err = mergeThread(src, dst, board, by)
+	if err != nil || IsTest {
+		return
+	}
+	common.RedirectThread(src, board, dst)
+	return
+}
+
+func mergeThread(src, dst uint64, board, by string) (
+	posts []uint64, err error,
+) {
+	tx, err := BeginTx()
+	if err != nil {
+		return
+	}
+	defer EndTx(tx, &err)
+
+	r, err := getStatement(tx, "merge_thread_posts").Query(src, dst, board)
+	if err != nil {
+		return
+	}
+	defer r.Close()
+	for r.Next() {
+		var id uint64
+		err = r.Scan(&id)
+		if err != nil {
+			return
+		}
+		posts = append(posts, id)
+	}
+	err = r.Err()
+	if err != nil {
+		return
+	}
+	sort.Slice(posts, func(i, j int) bool {
+		return posts[i] < posts[j]
+	})
+	err = execPreparedTx(tx, "merge_thread", src, dst, by)
+	if err != nil {
+		return
+	}
+	err = execPreparedTx(tx, "relink_thread_posts", dst)
+	return
 }
 
 // GetOwnedBoards returns boards the account holder owns
@@ -291,25 +692,31 @@ func GetBans(tx *sql.Tx, boards []string) (bans auth.BanRecords, err error) {
 	}
 	defer rs.Close()
 	for rs.Next() {
-		var rec auth.BanRecord
-		var expires time.Time
-		err = rs.Scan(&rec.Board, &rec.IP, &rec.ID, &rec.By, &expires, &rec.Reason)
+		var (
+			rec      auth.BanRecord
+			expires  time.Time
+			uniqueID sql.NullString
+		)
+		err = rs.Scan(&rec.Board, &rec.IP, &rec.ID, &rec.By, &expires,
+			&rec.Reason, &uniqueID, &rec.Scope)
 		if err != nil {
 			return
 		}
 		rec.Expires = expires.Unix()
+		rec.UniqueID = uniqueID.String
 		bans = append(bans, rec)
 	}
 	err = rs.Err()
 	return
 }
 
-// GetBanInfo retrieves information about a specific ban
-func GetBanInfo(ip, board string) (b auth.BanRecord, err error) {
+// GetBanInfo retrieves information about the ban matching an IP or unique ID
+// on a board. The narrowest ban is returned, if several match.
+func GetBanInfo(ip, board, uniqueID string) (b auth.BanRecord, err error) {
 	var expires time.Time
 	err = prepared["get_ban_info"].
-		QueryRow(ip, board).
-		Scan(&b.Board, &b.IP, &b.ID, &b.Reason, &b.By, &expires)
+		QueryRow(ip, board, uniqueID).
+		Scan(&b.IP, &b.Board, &b.ID, &b.Reason, &b.By, &expires)
 	b.Expires = expires.Unix()
 	return
 }
@@ -322,7 +729,12 @@ func WriteBans(tx *sql.Tx, board string, bans auth.BanRecords) (err error) {
 	st := getStatement(tx, "write_ban")
 	for _, rec := range bans {
 		expires := time.Unix(rec.Expires, 0)
-		_, err = st.Exec(board, rec.IP, rec.ID, rec.By, expires, rec.Reason, nil)
+		var uniqueID *string
+		if rec.UniqueID != "" {
+			uniqueID = &rec.UniqueID
+		}
+		_, err = st.Exec(board, rec.IP, rec.ID, rec.By, expires, rec.Reason,
+			uniqueID, rec.Scope, banDuration(expires))
 		if err != nil {
 			return
 		}
@@ -331,23 +743,62 @@ func WriteBans(tx *sql.Tx, board string, bans auth.BanRecords) (err error) {
 	return
 }
 
+// ModLogFilter narrows down retrieved moderation log entries. Zero values
+// match all entries.
+type ModLogFilter struct {
+	By    string
+	Types []auth.ModerationAction
+	// Entries created in [From, To)
+	From, To time.Time
+}
+
+// Convert time to a nullable UTC timestamp parameter
+func nullableTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC()
+}
+
 // GetModLog retrieves moderation log for the specified boards.
 // TODO(Kagami): Pagination.
-func GetModLog(boards []string) (log auth.ModLogRecords, err error) {
+func GetModLog(boards []string, f ModLogFilter) (
+	log auth.ModLogRecords, err error,
+) {
+	types := make([]int64, len(f.Types))
+	for i, t := range f.Types {
+		types[i] = int64(t)
+	}
+
 	log = make(auth.ModLogRecords, 0)
-	rs, err := prepared["get_mod_log"].Query(pq.Array(boards))
+	rs, err := prepared["get_mod_log"].Query(pq.Array(boards), f.By,
+		pq.Array(types), nullableTime(f.From), nullableTime(f.To))
 	if err != nil {
 		return
 	}
 	defer rs.Close()
 	for rs.Next() {
-		var rec auth.ModLogRecord
-		var created time.Time
-		err = rs.Scan(&rec.Board, &rec.ID, &rec.Type, &rec.By, &created)
+		var (
+			rec          auth.ModLogRecord
+			created      time.Time
+			reason, body sql.NullString
+			duration     sql.NullInt64
+			files        pq.StringArray
+			postIDs      pq.Int64Array
+		)
+		err = rs.Scan(&rec.Board, &rec.ID, &rec.Type, &rec.By, &created,
+			&reason, &duration, &body, &files, &postIDs)
 		if err != nil {
 			return
 		}
+		for _, id := range postIDs {
+			rec.PostIDs = append(rec.PostIDs, uint64(id))
+		}
 		rec.Created = created.Unix()
+		rec.Reason = reason.String
+		rec.Duration = duration.Int64
+		rec.PostBody = body.String
+		rec.PostFiles = []string(files)
 		log = append(log, rec)
 	}
 	err = rs.Err()
@@ -0,0 +1,324 @@
+package db
+
+import (
+	"database/sql"
+	"meguca/common"
+	"meguca/config"
+	. "meguca/test"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Write boards a and b, threads 1 and 3 on board a with replies 2 and 4
+// and post 4 linking post 2.
+func writeMoveSample(t *testing.T) {
+	assertTableClear(t, "boards")
+	for _, id := range [...]string{"a", "b"} {
+		c := config.BoardConfig{
+			BoardPublic: config.BoardPublic{ID: id},
+		}
+		if err := WriteBoard(nil, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Now().Unix()
+	post := func(id, op uint64, links common.Links) Post {
+		return Post{
+			StandalonePost: common.StandalonePost{
+				Post: common.Post{
+					ID:    id,
+					Time:  now,
+					Body:  "foo",
+					Links: links,
+				},
+				OP:    op,
+				Board: "a",
+			},
+		}
+	}
+	for _, op := range [...]uint64{1, 3} {
+		if err := InsertThread(nil, post(op, op, nil), "subject"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := InsertPost(nil, post(2, 1, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = InsertPost(nil, post(4, 3, common.Links{{ID: 2, OP: 1, Board: "a"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func assertPostParenthood(t *testing.T, id, op uint64, board string) {
+	t.Helper()
+	b, o, err := GetPostParenthood(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertDeepEquals(t, [2]interface{}{b, o}, [2]interface{}{board, op})
+}
+
+func assertPostLinks(t *testing.T, id uint64, links common.Links) {
+	t.Helper()
+	p, err := GetPost(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertDeepEquals(t, p.Links, links)
+}
+
+func TestMoveThread(t *testing.T) {
+	writeMoveSample(t)
+
+	if err := MoveThread(1, "a", "b", "admin"); err != nil {
+		t.Fatal(err)
+	}
+
+	assertPostParenthood(t, 1, 1, "b")
+	assertPostParenthood(t, 2, 1, "b")
+	assertPostParenthood(t, 3, 3, "a")
+	assertPostLinks(t, 4, common.Links{{ID: 2, OP: 1, Board: "b"}})
+}
+
+func TestMergeThread(t *testing.T) {
+	writeMoveSample(t)
+
+	posts, err := MergeThread(1, 3, "a", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertDeepEquals(t, posts, []uint64{1, 2})
+
+	assertPostParenthood(t, 1, 3, "a")
+	assertPostParenthood(t, 2, 3, "a")
+	assertPostLinks(t, 4, common.Links{{ID: 2, OP: 3, Board: "a"}})
+	if _, err := GetThreadState(nil, 1); err == nil {
+		t.Fatal("source thread not deleted")
+	}
+}
+
+func TestSetThreadFlags(t *testing.T) {
+	writeMoveSample(t)
+
+	cases := [...]struct {
+		name string
+		set  func(id uint64, val bool, by string) error
+		col  string
+	}{
+		{"sticky", SetThreadSticky, "sticky"},
+		{"locked", SetThreadLocked, "locked"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := c.set(1, true, "admin"); err != nil {
+				t.Fatal(err)
+			}
+			var val bool
+			err := db.QueryRow(`select ` + c.col + ` from threads where id = 1`).
+				Scan(&val)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !val {
+				t.Fatalf("%s not set", c.col)
+			}
+
+			// Replies are not threads
+			if err := c.set(2, true, "admin"); err != sql.ErrNoRows {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestSelfDeletePost(t *testing.T) {
+	writeMoveSample(t)
+
+	if err := SelfDeletePost(2, "self"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetPost(2); err != sql.ErrNoRows {
+		t.Fatalf("deleted post not hidden: %v", err)
+	}
+	if _, err := GetPostAuthorInfo(2); err != sql.ErrNoRows {
+		t.Fatalf("deleted post author info retrieved: %v", err)
+	}
+
+	// Self-deleted posts are not held and can be restored by moderators
+	if err := ApprovePost(2, "admin"); err != sql.ErrNoRows {
+		t.Fatalf("self-deleted post approved: %v", err)
+	}
+	if err := UndeletePost(2, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetPostAuthorInfo(2); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Write boards a and b with posts by two IPs and two unique IDs:
+//
+//	1: thread on a, ::1, u1, an hour ago
+//	2: reply to 1, ::1, u1, now
+//	3: reply to 1, ::2, u1, now
+//	4: reply to 5, ::1, u2, two hours ago
+//	5: thread on a, ::2, u2, now
+//	6: thread on b, ::1, u1, now
+func writeNukeSample(t *testing.T, now int64) {
+	assertTableClear(t, "boards")
+	for _, id := range [...]string{"a", "b"} {
+		c := config.BoardConfig{
+			BoardPublic: config.BoardPublic{ID: id},
+		}
+		if err := WriteBoard(nil, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	post := func(id, op uint64, board, ip, uniqueID string, time int64) Post {
+		return Post{
+			StandalonePost: common.StandalonePost{
+				Post: common.Post{
+					ID:   id,
+					Time: time,
+					Body: "foo",
+				},
+				OP:    op,
+				Board: board,
+			},
+			IP:       ip,
+			UniqueID: uniqueID,
+		}
+	}
+	threads := [...]Post{
+		post(1, 1, "a", "::1", "u1", now-3600),
+		post(5, 5, "a", "::2", "u2", now),
+		post(6, 6, "b", "::1", "u1", now),
+	}
+	for _, p := range threads {
+		if err := InsertThread(nil, p, "subject"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	replies := [...]Post{
+		post(2, 1, "a", "::1", "u1", now),
+		post(3, 1, "a", "::2", "u1", now),
+		post(4, 5, "a", "::1", "u2", now-7200),
+	}
+	for _, p := range replies {
+		if err := InsertPost(nil, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestNukePosts(t *testing.T) {
+	now := time.Now().Unix()
+
+	// Replies in a nuked thread are deleted with it, so 3 is deleted
+	// together with 1
+	cases := [...]struct {
+		name       string
+		byUniqueID bool
+		since      int64
+		ban        bool
+		ids        []uint64
+	}{
+		{
+			name: "by IP",
+			ids:  []uint64{1, 2, 3, 4},
+		},
+		{
+			name:  "by IP in time window",
+			since: now - 5400,
+			ids:   []uint64{1, 2, 3},
+		},
+		{
+			name:       "by unique ID",
+			byUniqueID: true,
+			ids:        []uint64{1, 2, 3},
+		},
+		{
+			name:       "by unique ID in time window",
+			byUniqueID: true,
+			since:      now - 1800,
+			ids:        []uint64{2, 3},
+		},
+		{
+			name:  "with ban",
+			since: now - 1800,
+			ban:   true,
+			ids:   []uint64{2},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			writeNukeSample(t, now)
+			assertTableClear(t, "bans", "mod_log")
+
+			var ban *NukeBan
+			if c.ban {
+				ban = &NukeBan{
+					Reason:  "spam",
+					Expires: time.Now().Add(time.Hour),
+				}
+			}
+			ids, ips, err := NukePosts(2, "a", c.byUniqueID, c.since, "admin",
+				ban)
+			if err != nil {
+				t.Fatal(err)
+			}
+			AssertDeepEquals(t, ids, c.ids)
+
+			// The author is banned for the target post
+			var bans int
+			err = db.QueryRow(`select count(*) from bans where forPost = 2`).
+				Scan(&bans)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.ban {
+				AssertDeepEquals(t, ips, map[string]uint64{"::1": 2})
+				if bans != 1 {
+					t.Fatalf("expected 1 ban, got %d", bans)
+				}
+			} else if bans != 0 {
+				t.Fatalf("unexpected bans: %d", bans)
+			}
+
+			deleted := make(map[uint64]bool, len(ids))
+			for _, id := range ids {
+				deleted[id] = true
+			}
+			for id := uint64(1); id <= 6; id++ {
+				_, err := GetPost(id)
+				switch {
+				case deleted[id] && err != sql.ErrNoRows:
+					t.Errorf("post %d not deleted: %v", id, err)
+				case !deleted[id] && err != nil:
+					t.Errorf("post %d deleted: %v", id, err)
+				}
+			}
+
+			// A single log entry references all nuked posts
+			var logged pq.Int64Array
+			err = db.QueryRow(`select post_ids from mod_log where type = 17`).
+				Scan(&logged)
+			if err != nil {
+				t.Fatal(err)
+			}
+			std := make(pq.Int64Array, len(c.ids))
+			for i, id := range c.ids {
+				std[i] = int64(id)
+			}
+			AssertDeepEquals(t, logged, std)
+		})
+	}
+}
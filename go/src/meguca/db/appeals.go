@@ -0,0 +1,73 @@
+// Appeals of banned users
+
+package db
+
+import (
+	"time"
+
+	"meguca/auth"
+)
+
+// InsertAppeal writes an appeal against a ban. Only one appeal per ban is
+// allowed.
+func InsertAppeal(ban auth.BanRecord, text string) error {
+	return execPrepared("insert_appeal", ban.Board, ban.IP, ban.ID, text,
+		time.Now().Unix())
+}
+
+// GetAppeal retrieves the appeal against a ban, if any
+func GetAppeal(ban auth.BanRecord) (a auth.BanAppeal, err error) {
+	err = prepared["get_appeal"].
+		QueryRow(ban.Board, ban.IP, ban.ID).
+		Scan(&a.ID, &a.Board, &a.BanID, &a.Text, &a.Created, &a.Resolved,
+			&a.Accepted)
+	a.Reason = ban.Reason
+	return
+}
+
+// GetAppeals retrieves unresolved appeals against current bans of a board
+func GetAppeals(board string) (appeals auth.BanAppeals, err error) {
+	appeals = make(auth.BanAppeals, 0)
+	r, err := prepared["get_appeals"].Query(board)
+	if err != nil {
+		return
+	}
+	defer r.Close()
+	for r.Next() {
+		var a auth.BanAppeal
+		err = r.Scan(&a.ID, &a.Board, &a.BanID, &a.Reason, &a.Text,
+			&a.Created, &a.Resolved, &a.Accepted)
+		if err != nil {
+			return
+		}
+		appeals = append(appeals, a)
+	}
+	err = r.Err()
+	return
+}
+
+// ResolveAppeal accepts or denies an appeal and records the decision in
+// the moderation log. Accepted appeals lift the ban. Returns
+// sql.ErrNoRows, if there is no such unresolved appeal.
+func ResolveAppeal(board string, id uint64, accept bool, by string) (
+	err error,
+) {
+	tx, err := BeginTx()
+	if err != nil {
+		return
+	}
+	defer EndTx(tx, &err)
+
+	var (
+		banID uint64
+		dummy interface{}
+	)
+	err = getStatement(tx, "resolve_appeal").
+		QueryRow(board, id, accept, by).
+		Scan(&banID, &dummy)
+	if err != nil || !accept {
+		return
+	}
+	err = execPreparedTx(tx, "unban", board, banID, by)
+	return
+}
@@ -0,0 +1,169 @@
+package db
+
+import (
+	"database/sql"
+	"meguca/auth"
+	. "meguca/test"
+	"testing"
+)
+
+// Write board a with bans of IP ::1 for post 1 and IP ::2 for post 2
+func writeAppealSample(t *testing.T) {
+	assertTableClear(t, "boards", "bans", "mod_log")
+	writeSampleBoard(t)
+	for i, ip := range [...]string{"::1", "::2"} {
+		assertExec(t,
+			`insert into bans (board, ip, forPost, by, reason, expires)
+				values ('a', $1, $2, 'admin', 'spam',
+					now() at time zone 'utc' + interval '1 day')`,
+			ip, i+1)
+	}
+}
+
+func TestGetBanInfo(t *testing.T) {
+	writeAppealSample(t)
+
+	cases := [...]struct {
+		name, ip, board string
+		err             error
+		id              uint64
+	}{
+		{"banned", "::1", "a", nil, 1},
+		{"other IP", "::3", "a", sql.ErrNoRows, 0},
+		{"other board", "::1", "c", sql.ErrNoRows, 0},
+	}
+
+	for i := range cases {
+		c := cases[i]
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			b, err := GetBanInfo(c.ip, c.board, "")
+			if err != c.err {
+				UnexpectedError(t, err)
+			}
+			if c.err != nil {
+				return
+			}
+			// Columns are scanned in the order selected by get_ban_info
+			AssertDeepEquals(t, b.Ban, auth.Ban{IP: c.ip, Board: c.board})
+			AssertDeepEquals(t, b.ID, c.id)
+			AssertDeepEquals(t, b.Reason, "spam")
+			AssertDeepEquals(t, b.By, "admin")
+		})
+	}
+}
+
+func assertBanned(t *testing.T, ip string, std bool) {
+	t.Helper()
+	_, err := GetBanInfo(ip, "a", "")
+	switch err {
+	case nil, sql.ErrNoRows:
+		if banned := err == nil; banned != std {
+			t.Fatalf("unexpected ban state: %t : %t", std, banned)
+		}
+	default:
+		t.Fatal(err)
+	}
+}
+
+func TestInsertAppeal(t *testing.T) {
+	writeAppealSample(t)
+	ban, err := GetBanInfo("::1", "a", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := InsertAppeal(ban, "sorry"); err != nil {
+		t.Fatal(err)
+	}
+	a, err := GetAppeal(ban)
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertDeepEquals(t, a, auth.BanAppeal{
+		ID:      a.ID,
+		Board:   "a",
+		BanID:   1,
+		Reason:  "spam",
+		Text:    "sorry",
+		Created: a.Created,
+	})
+
+	appeals, err := GetAppeals("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertDeepEquals(t, appeals, auth.BanAppeals{a})
+
+	// Only one appeal per ban
+	if err := InsertAppeal(ban, "sorry again"); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestResolveAppeal(t *testing.T) {
+	writeAppealSample(t)
+	ids := make(map[string]uint64, 2)
+	for _, ip := range [...]string{"::1", "::2"} {
+		ban, err := GetBanInfo(ip, "a", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := InsertAppeal(ban, "sorry"); err != nil {
+			t.Fatal(err)
+		}
+		a, err := GetAppeal(ban)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[ip] = a.ID
+	}
+
+	cases := [...]struct {
+		name, ip string
+		accept   bool
+		banned   bool
+		logType  auth.ModerationAction
+	}{
+		{"accept", "::1", true, false, auth.AcceptAppeal},
+		{"deny", "::2", false, true, auth.DenyAppeal},
+	}
+
+	for i := range cases {
+		c := cases[i]
+		t.Run(c.name, func(t *testing.T) {
+			err := ResolveAppeal("a", ids[c.ip], c.accept, "mod")
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertBanned(t, c.ip, c.banned)
+
+			var (
+				by    string
+				count int
+			)
+			const q = `select by, count(*) over () from mod_log
+				where type = $1 and board = 'a' and id = $2`
+			err = db.QueryRow(q, c.logType, i+1).Scan(&by, &count)
+			if err != nil {
+				t.Fatal(err)
+			}
+			AssertDeepEquals(t, by, "mod")
+			AssertDeepEquals(t, count, 1)
+
+			// Already resolved
+			err = ResolveAppeal("a", ids[c.ip], c.accept, "mod")
+			if err != sql.ErrNoRows {
+				UnexpectedError(t, err)
+			}
+		})
+	}
+
+	t.Run("queue emptied", func(t *testing.T) {
+		appeals, err := GetAppeals("a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		AssertDeepEquals(t, appeals, auth.BanAppeals{})
+	})
+}
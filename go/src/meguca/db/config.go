@@ -3,6 +3,7 @@ package db
 import (
 	"database/sql"
 
+	"meguca/auth"
 	"meguca/config"
 	"meguca/util"
 )
@@ -16,6 +17,31 @@ func loadServerConfig() error {
 	return listenFunc("config_updates", updateServerConfig)
 }
 
+// Load the tripcode secret or generate it on first start
+func loadTripSecret() (err error) {
+	var secret string
+	err = db.QueryRow(`SELECT val FROM main WHERE id = 'trip_secret'`).Scan(&secret)
+	switch err {
+	case nil:
+	case sql.ErrNoRows:
+		secret, err = auth.RandomID(32)
+		if err != nil {
+			return
+		}
+		_, err = db.Exec(
+			`INSERT INTO main (id, val) VALUES ('trip_secret', $1)`,
+			secret,
+		)
+		if err != nil {
+			return
+		}
+	default:
+		return
+	}
+	auth.SetTripSecret(secret)
+	return
+}
+
 func getServerConfig() (c config.ServerConfig, err error) {
 	var data []byte
 	err = db.QueryRow(`SELECT val FROM main WHERE id = 'config'`).Scan(&data)
@@ -26,7 +52,9 @@ func getServerConfig() (c config.ServerConfig, err error) {
 	return
 }
 
+// Fields missing from the stored configuration keep their defaults
 func decodeServerConfig(data []byte) (c config.ServerConfig, err error) {
+	c = config.DefaultServerConfig
 	err = c.UnmarshalJSON(data)
 	return
 }
@@ -0,0 +1,106 @@
+// Post body editing and edit history
+
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"meguca/common"
+)
+
+// PostAuthorInfo contains the post data needed to authorize an edit or
+// deletion by the post's author
+type PostAuthorInfo struct {
+	OP       uint64
+	Board    string
+	Time     int64
+	UserID   string
+	Token    string
+	IP       string
+	Password []byte
+}
+
+// PostEdit is a previous version of a post's body
+type PostEdit struct {
+	Body string `json:"body"`
+	Time int64  `json:"time"`
+}
+
+// GetPostAuthorInfo retrieves the parenthood and authorship data of a post.
+// Returns sql.ErrNoRows for deleted posts.
+func GetPostAuthorInfo(id uint64) (p PostAuthorInfo, err error) {
+	var userID, token, ip sql.NullString
+	err = prepared["get_post_author_info"].
+		QueryRow(id).
+		Scan(&p.OP, &p.Board, &p.Time, &userID, &token, &ip, &p.Password)
+	p.UserID = userID.String
+	p.Token = token.String
+	p.IP = ip.String
+	return
+}
+
+// EditPost replaces the body of a post and writes the previous one to the
+// edit history
+func EditPost(
+	id uint64,
+	body string,
+	links common.Links,
+	commands common.Commands,
+) (err error) {
+	tx, err := BeginTx()
+	if err != nil {
+		return
+	}
+	defer EndTx(tx, &err)
+
+	err = execPreparedTx(tx, "write_post_edit", id, time.Now().Unix())
+	if err != nil {
+		return
+	}
+	err = execPreparedTx(tx, "replace_body", id, body,
+		linkRow(links), commandRow(commands))
+	if err != nil {
+		return
+	}
+	err = execPreparedTx(tx, "delete_post_links", id)
+	if err != nil {
+		return
+	}
+	err = writePostLinks(tx, id, links)
+	return
+}
+
+// GetPostEdits retrieves all previous bodies of a post, oldest first
+func GetPostEdits(id uint64) (edits []PostEdit, err error) {
+	r, err := prepared["get_post_edits"].Query(id)
+	if err != nil {
+		return
+	}
+	defer r.Close()
+
+	edits = make([]PostEdit, 0, 4)
+	for r.Next() {
+		var e PostEdit
+		err = r.Scan(&e.Body, &e.Time)
+		if err != nil {
+			return
+		}
+		edits = append(edits, e)
+	}
+	err = r.Err()
+	return
+}
+
+// Store the post token, deletion password and tripcode. The former two
+// allow anonymous authors to edit and delete their posts later.
+func writePostAuth(tx *sql.Tx, p Post) error {
+	if p.Token == "" && p.Password == nil && p.Trip == "" {
+		return nil
+	}
+	var trip *string
+	if p.Trip != "" {
+		trip = &p.Trip
+	}
+	return execPreparedTx(tx, "set_post_auth", p.ID, p.Token, p.Password, trip)
+}
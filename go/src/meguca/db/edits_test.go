@@ -0,0 +1,162 @@
+package db
+
+import (
+	"database/sql"
+	"meguca/common"
+	"meguca/config"
+	. "meguca/test"
+	"testing"
+	"time"
+)
+
+// Write board a with thread 1, reply 2 linking post 1 and thread 3
+func writeEditSample(t *testing.T, now int64) {
+	assertTableClear(t, "boards")
+	c := config.BoardConfig{
+		BoardPublic: config.BoardPublic{ID: "a"},
+	}
+	if err := WriteBoard(nil, c); err != nil {
+		t.Fatal(err)
+	}
+	post := func(id, op uint64, body string, links common.Links) Post {
+		return Post{
+			StandalonePost: common.StandalonePost{
+				Post: common.Post{
+					ID:    id,
+					Time:  now,
+					Body:  body,
+					Links: links,
+				},
+				OP:    op,
+				Board: "a",
+			},
+			IP:    "::1",
+			Token: "token",
+		}
+	}
+	for _, op := range [...]uint64{1, 3} {
+		if err := InsertThread(nil, post(op, op, "", nil), "s"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := InsertPost(nil, post(2, 1, ">>1", common.Links{
+		{ID: 1, OP: 1, Board: "a"},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetPostAuthorInfo(t *testing.T) {
+	now := time.Now().Unix()
+	writeEditSample(t, now)
+	assertExec(t, `update posts set deleted = true where id = 3`)
+
+	cases := [...]struct {
+		name string
+		id   uint64
+		err  error
+		std  PostAuthorInfo
+	}{
+		{
+			name: "reply",
+			id:   2,
+			std: PostAuthorInfo{
+				OP:    1,
+				Board: "a",
+				Time:  now,
+				Token: "token",
+				IP:    "::1",
+			},
+		},
+		{
+			name: "deleted post",
+			id:   3,
+			err:  sql.ErrNoRows,
+		},
+		{
+			name: "nonexistent post",
+			id:   99,
+			err:  sql.ErrNoRows,
+		},
+	}
+
+	for i := range cases {
+		c := cases[i]
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := GetPostAuthorInfo(c.id)
+			if err != c.err {
+				UnexpectedError(t, err)
+			}
+			if c.err == nil {
+				AssertDeepEquals(t, p, c.std)
+			}
+		})
+	}
+}
+
+func TestEditPost(t *testing.T) {
+	writeEditSample(t, time.Now().Unix())
+
+	// Run sequentially, as every edit adds to the history of post 2
+	cases := [...]struct {
+		name, body string
+		links      common.Links
+		// Posts linked by post 2 after the edit
+		linked []uint64
+		// Previous bodies of post 2
+		history []string
+	}{
+		{
+			name:    "remove links",
+			body:    "foo",
+			linked:  []uint64{},
+			history: []string{">>1"},
+		},
+		{
+			name:    "link other thread",
+			body:    ">>3",
+			links:   common.Links{{ID: 3, OP: 3, Board: "a"}},
+			linked:  []uint64{3},
+			history: []string{">>1", "foo"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := EditPost(2, c.body, c.links, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			p, err := GetPost(2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			AssertDeepEquals(t, p.Body, c.body)
+
+			linked := make([]uint64, 0, 1)
+			for _, id := range [...]uint64{1, 3} {
+				bls, err := GetBacklinks(nil, []uint64{id})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(bls[id]) != 0 {
+					linked = append(linked, id)
+				}
+			}
+			AssertDeepEquals(t, linked, c.linked)
+
+			edits, err := GetPostEdits(2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			history := make([]string, len(edits))
+			for i, e := range edits {
+				history[i] = e.Body
+			}
+			AssertDeepEquals(t, history, c.history)
+		})
+	}
+}
@@ -271,6 +271,216 @@ var upgrades = []func(*sql.Tx) error{
 			ALTER TABLE smiles ADD COLUMN readonly boolean default false;`,
 		)
 	},
+	// Thread archive.
+	func(tx *sql.Tx) (err error) {
+		return execAll(tx, `
+			ALTER TABLE threads ADD COLUMN archived boolean NOT NULL DEFAULT false;
+			create index threads_archived on threads (archived);`,
+		)
+	},
+	// Post editing.
+	func(tx *sql.Tx) (err error) {
+		return execAll(tx, `
+			ALTER TABLE posts ADD COLUMN token char(20);
+			create table post_edits (
+				id bigserial primary key,
+				post_id bigint not null references posts on delete cascade,
+				body text not null,
+				time bigint not null
+			);
+			create index post_edits_post_id on post_edits (post_id);`,
+		)
+	},
+	// Thread locking.
+	func(tx *sql.Tx) (err error) {
+		return execAll(tx, `
+			ALTER TABLE threads ADD COLUMN locked boolean NOT NULL DEFAULT false;`,
+		)
+	},
+	// Thread polls.
+	func(tx *sql.Tx) (err error) {
+		return execAll(tx, `
+			create table polls (
+				thread bigint primary key references threads on delete cascade,
+				question varchar(200) not null,
+				options text[] not null,
+				multiple boolean not null default false,
+				expires bigint
+			);
+			create table poll_votes (
+				thread bigint not null references polls on delete cascade,
+				voter text not null,
+				options smallint[] not null,
+				primary key (thread, voter)
+			);`,
+		)
+	},
+	// Full-text search.
+	func(tx *sql.Tx) (err error) {
+		return execAll(tx, `
+			create index posts_body_fts on posts using gin (to_tsvector('simple', body));
+			create index threads_subject_fts on threads using gin (to_tsvector('simple', subject));`,
+		)
+	},
+	// Store board of the linked posts.
+	func(tx *sql.Tx) (err error) {
+		return execAll(tx, `
+			ALTER TABLE posts ADD COLUMN links_json json[];
+			UPDATE posts p SET links_json = (
+				select array_agg(json_build_array(p.links[i][1], p.links[i][2], coalesce(t.board, '')) order by i)
+				from generate_subscripts(p.links, 1) i
+				left join threads t on t.id = p.links[i][2]
+			) where p.links is not null;
+			ALTER TABLE posts DROP COLUMN links;
+			ALTER TABLE posts RENAME COLUMN links_json TO links;
+			DROP FUNCTION IF EXISTS insert_thread(bigint, bigint, bigint, text, varchar, varchar, text, inet, text, bigint[], json[], bigint, varchar);`,
+		)
+	},
+	// Backlinks.
+	func(tx *sql.Tx) (err error) {
+		return execAll(tx, `
+			create table post_links (
+				source bigint not null references posts on delete cascade,
+				target bigint not null references posts on delete cascade,
+				primary key (source, target)
+			);
+			create index post_links_target on post_links (target);
+			insert into post_links (source, target)
+				select p.id, (l->>0)::bigint
+				from posts p, unnest(p.links) l
+				where exists (select 1 from posts where id = (l->>0)::bigint)
+				on conflict do nothing;`,
+		)
+	},
+	// Post reports.
+	func(tx *sql.Tx) (err error) {
+		return execAll(tx, `
+			create table reports (
+				id bigserial primary key,
+				post_id bigint not null references posts on delete cascade,
+				board text not null references boards on delete cascade,
+				reason varchar(100) not null,
+				ip inet not null,
+				created bigint not null,
+				resolved boolean not null default false,
+				resolved_by varchar(20),
+				unique (post_id, ip)
+			);
+			create index reports_board on reports (board);
+			create index reports_ip on reports (ip);`,
+		)
+	},
+	// Reports filed by the server have no reporter IP.
+	func(tx *sql.Tx) (err error) {
+		return execAll(tx,
+			`alter table reports alter column ip drop not null`,
+		)
+	},
+	// Recent posts lookup for spam detection.
+	func(tx *sql.Tx) (err error) {
+		return execAll(tx,
+			`create index posts_time on posts (time)`,
+		)
+	},
+	// Reply notifications.
+	func(tx *sql.Tx) (err error) {
+		return execAll(tx, `
+			create table notifications (
+				id bigserial primary key,
+				account varchar(20) not null references accounts on delete cascade,
+				post_id bigint not null references posts on delete cascade,
+				target bigint not null references posts on delete cascade,
+				read boolean not null default false,
+				unique (post_id, target)
+			);
+			create index notifications_account on notifications (account, id);`,
+		)
+	},
+	// Thread watching.
+	func(tx *sql.Tx) (err error) {
+		return execAll(tx, `
+			create table watched_threads (
+				account varchar(20) not null references accounts on delete cascade,
+				thread bigint not null references threads on delete cascade,
+				-- Post counter of the thread, when last viewed
+				seen bigint not null,
+				primary key (account, thread)
+			);
+			create index watched_threads_thread on watched_threads (thread);`,
+		)
+	},
+	// Ban appeals.
+	func(tx *sql.Tx) (err error) {
+		return execAll(tx, `
+			create table ban_appeals (
+				id bigserial primary key,
+				board text not null references boards on delete cascade,
+				ip inet not null,
+				-- Post the appealed ban was issued for
+				ban_id bigint not null,
+				text varchar(1000) not null,
+				created bigint not null,
+				resolved boolean not null default false,
+				accepted boolean not null default false,
+				resolved_by varchar(20),
+				unique (board, ip, ban_id)
+			);
+			create index ban_appeals_board on ban_appeals (board);`,
+		)
+	},
+	// Range and unique ID bans. Range bans store the network in the ip
+	// column.
+	func(tx *sql.Tx) (err error) {
+		return execAll(tx,
+			`alter table bans add column scope smallint not null default 0`,
+			`create index bans_unique_id on bans (unique_id)
+				where scope = 2`,
+		)
+	},
+	// Ban details and post snapshots in the moderation log.
+	func(tx *sql.Tx) (err error) {
+		return execAll(tx,
+			`alter table mod_log
+				add column reason text,
+				add column duration bigint,
+				add column post_body text,
+				add column post_files char(40)[]`,
+			`create index mod_log_by on mod_log (by)`,
+		)
+	},
+	// Restorable post deletion. Deleted posts are kept with the deleted flag
+	// set.
+	func(tx *sql.Tx) (err error) {
+		return execAll(tx,
+			`alter table posts
+				add column deleted_at bigint,
+				add column deleted_by varchar(20)`,
+			`create index posts_deleted on posts (board, deleted_at)
+				where deleted`,
+		)
+	},
+	// Posts affected by bulk moderation actions.
+	func(tx *sql.Tx) (err error) {
+		return execAll(tx,
+			`alter table mod_log add column post_ids bigint[]`,
+		)
+	},
+	// Posts held by word filters. These used to be told apart from deleted
+	// posts only by the missing deleted_by, so carry over those with an open
+	// server report.
+	func(tx *sql.Tx) (err error) {
+		return execAll(tx,
+			`alter table posts add column held boolean not null default false`,
+			`update posts p
+				set held = true
+				where p.deleted and p.deleted_by is null
+					and exists (
+						select 1 from reports r
+							where r.post_id = p.id and r.ip is null
+								and not r.resolved
+					)`,
+		)
+	},
 }
 
 func StartDB() (err error) {
@@ -296,6 +506,7 @@ func StartDB() (err error) {
 		tasks = append(tasks, createAdminAccount)
 	}
 	tasks = append(tasks, loadServerConfig, loadBoardConfigs, loadBans)
+	tasks = append(tasks, loadTripSecret)
 	if err = util.Waterfall(tasks...); err != nil {
 		return
 	}
@@ -0,0 +1,79 @@
+// Reply notifications of accounts
+
+package db
+
+import (
+	"database/sql"
+
+	"meguca/auth"
+	"meguca/common"
+
+	"github.com/lib/pq"
+)
+
+// Notify accounts, whose posts are linked by a new post. Authors are not
+// notified about replies to themselves.
+func writeNotifications(tx *sql.Tx, p Post) error {
+	if len(p.Links) == 0 {
+		return nil
+	}
+	ids := make([]uint64, len(p.Links))
+	for i, l := range p.Links {
+		ids[i] = l.ID
+	}
+	return execPreparedTx(tx, "insert_notifications",
+		p.ID, pq.Array(ids), p.UserID)
+}
+
+// GetNotifications retrieves the latest notifications of an account and
+// the number of unread ones
+func GetNotifications(userID string) (
+	ns auth.Notifications, unread int, err error,
+) {
+	r, err := prepared["get_notifications"].
+		Query(userID, common.NotificationsPerPage)
+	if err != nil {
+		return
+	}
+	ns, err = scanNotifications(r)
+	if err != nil {
+		return
+	}
+	err = prepared["count_unread_notifications"].QueryRow(userID).
+		Scan(&unread)
+	return
+}
+
+// GetPostNotifications retrieves notifications created by a post
+func GetPostNotifications(id uint64) (auth.Notifications, error) {
+	r, err := prepared["get_post_notifications"].Query(id)
+	if err != nil {
+		return nil, err
+	}
+	return scanNotifications(r)
+}
+
+func scanNotifications(r *sql.Rows) (ns auth.Notifications, err error) {
+	defer r.Close()
+	ns = make(auth.Notifications, 0, 16)
+	for r.Next() {
+		var n auth.Notification
+		err = r.Scan(&n.ID, &n.Post, &n.OP, &n.Board, &n.Target, &n.Time,
+			&n.Read, &n.UserID)
+		if err != nil {
+			return
+		}
+		ns = append(ns, n)
+	}
+	err = r.Err()
+	return
+}
+
+// ReadNotifications marks notifications of an account as read. If ids is
+// empty, all of them are marked.
+func ReadNotifications(userID string, ids []uint64) error {
+	if len(ids) == 0 {
+		return execPrepared("read_all_notifications", userID)
+	}
+	return execPrepared("read_notifications", userID, pq.Array(ids))
+}
@@ -0,0 +1,132 @@
+package db
+
+import (
+	"meguca/common"
+	"meguca/config"
+	. "meguca/test"
+	"sort"
+	"testing"
+	"time"
+)
+
+// Write board a with thread 1 by account "alice" and reply 2 by account
+// "bob"
+func writeNotificationSample(t *testing.T) {
+	assertTableClear(t, "boards", "accounts")
+	for _, id := range [...]string{"alice", "bob"} {
+		if err := RegisterAccount(id, []byte{1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := config.BoardConfig{
+		BoardPublic: config.BoardPublic{ID: "a"},
+	}
+	if err := WriteBoard(nil, c); err != nil {
+		t.Fatal(err)
+	}
+	err := InsertThread(nil, notificationPost(1, "alice"), "subject")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := InsertPost(nil, notificationPost(2, "bob")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func notificationPost(id uint64, userID string, links ...uint64) Post {
+	p := Post{
+		StandalonePost: common.StandalonePost{
+			Post: common.Post{
+				ID:     id,
+				Time:   time.Now().Unix(),
+				UserID: userID,
+			},
+			OP:    1,
+			Board: "a",
+		},
+	}
+	for _, l := range links {
+		p.Links = append(p.Links, common.Link{ID: l, OP: 1, Board: "a"})
+	}
+	return p
+}
+
+func TestInsertNotifications(t *testing.T) {
+	writeNotificationSample(t)
+
+	cases := [...]struct {
+		name   string
+		id     uint64
+		userID string
+		links  []uint64
+		// Notified account per target post
+		notified map[uint64]string
+	}{
+		{"no links", 3, "bob", nil, map[uint64]string{}},
+		{"anonymous post", 4, "", []uint64{2}, map[uint64]string{2: "bob"}},
+		{"anonymous author", 5, "", []uint64{4}, map[uint64]string{}},
+		{"reply to self", 6, "alice", []uint64{1}, map[uint64]string{}},
+		{
+			"multiple accounts", 7, "", []uint64{1, 2},
+			map[uint64]string{1: "alice", 2: "bob"},
+		},
+	}
+
+	// Run sequentially, as later cases link earlier posts
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := notificationPost(c.id, c.userID, c.links...)
+			if err := InsertPost(nil, p); err != nil {
+				t.Fatal(err)
+			}
+
+			ns, err := GetPostNotifications(c.id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			notified := make(map[uint64]string, len(ns))
+			for _, n := range ns {
+				if n.Post != c.id || n.OP != 1 || n.Board != "a" || n.Read {
+					t.Errorf("unexpected notification: %#v", n)
+				}
+				notified[n.Target] = n.UserID
+			}
+			AssertDeepEquals(t, notified, c.notified)
+		})
+	}
+
+	t.Run("read", func(t *testing.T) {
+		ns, unread, err := GetNotifications("alice")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ns) != 1 || unread != 1 {
+			t.Fatalf("unexpected notifications: %d unread of %#v", unread, ns)
+		}
+
+		if err := ReadNotifications("alice", nil); err != nil {
+			t.Fatal(err)
+		}
+		_, unread, err = GetNotifications("alice")
+		if err != nil {
+			t.Fatal(err)
+		}
+		AssertDeepEquals(t, unread, 0)
+	})
+
+	t.Run("deleted reply", func(t *testing.T) {
+		assertExec(t, `update posts set deleted = true where id = 4`)
+		ns, _, err := GetNotifications("bob")
+		if err != nil {
+			t.Fatal(err)
+		}
+		posts := make([]uint64, len(ns))
+		for i, n := range ns {
+			posts[i] = n.Post
+		}
+		sort.Slice(posts, func(i, j int) bool {
+			return posts[i] < posts[j]
+		})
+		AssertDeepEquals(t, posts, []uint64{7})
+	})
+}
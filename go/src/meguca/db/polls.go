@@ -0,0 +1,81 @@
+// Thread polls and votes
+
+package db
+
+import (
+	"database/sql"
+
+	"meguca/common"
+
+	"github.com/lib/pq"
+)
+
+// InsertPoll writes a poll attached to thread op
+func InsertPoll(tx *sql.Tx, op uint64, p common.Poll) error {
+	var expires *int64
+	if p.Expires != 0 {
+		expires = &p.Expires
+	}
+	return execPreparedTx(tx, "insert_poll", op, p.Question,
+		pq.StringArray(p.Options), p.Multiple, expires)
+}
+
+// GetPoll retrieves the poll of a thread with its current tallies. Returns
+// nil, if the thread has no poll.
+func GetPoll(tx *sql.Tx, op uint64) (p *common.Poll, err error) {
+	var (
+		options pq.StringArray
+		expires sql.NullInt64
+		poll    common.Poll
+	)
+	err = getStatement(tx, "get_poll").
+		QueryRow(op).
+		Scan(&poll.Question, &options, &poll.Multiple, &expires)
+	switch err {
+	case nil:
+	case sql.ErrNoRows:
+		return nil, nil
+	default:
+		return
+	}
+	poll.Options = []string(options)
+	poll.Expires = expires.Int64
+	poll.Votes, err = GetPollVotes(tx, op, len(options))
+	if err != nil {
+		return
+	}
+	return &poll, nil
+}
+
+// GetPollVotes counts the votes for each of the n options of a thread poll
+func GetPollVotes(tx *sql.Tx, op uint64, n int) (votes []uint64, err error) {
+	r, err := getStatement(tx, "get_poll_votes").Query(op)
+	if err != nil {
+		return
+	}
+	defer r.Close()
+
+	votes = make([]uint64, n)
+	for r.Next() {
+		var (
+			i   int
+			cnt uint64
+		)
+		err = r.Scan(&i, &cnt)
+		if err != nil {
+			return
+		}
+		if i >= 0 && i < n {
+			votes[i] = cnt
+		}
+	}
+	err = r.Err()
+	return
+}
+
+// InsertPollVote records a vote for the passed options. Each voter is
+// identified by their IP and may only vote once, so repeated votes produce a
+// unique constraint violation.
+func InsertPollVote(op uint64, ip string, options []int64) error {
+	return execPrepared("insert_poll_vote", op, "ip:"+ip, pq.Array(options))
+}
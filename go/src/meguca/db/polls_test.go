@@ -0,0 +1,50 @@
+package db
+
+import (
+	"meguca/common"
+	. "meguca/test"
+	"testing"
+)
+
+func TestInsertPollVote(t *testing.T) {
+	writeMoveSample(t)
+	err := InsertPoll(nil, 1, common.Poll{
+		Question: "foo?",
+		Options:  []string{"bar", "baz"},
+		Multiple: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Run sequentially, as all cases vote in the same poll
+	cases := [...]struct {
+		name, ip string
+		options  []int64
+		conflict bool
+		votes    []uint64
+	}{
+		{"first vote", "::1", []int64{0}, false, []uint64{1, 0}},
+		{"other IP", "::2", []int64{0, 1}, false, []uint64{2, 1}},
+		{"repeated vote", "::1", []int64{1}, true, []uint64{2, 1}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := InsertPollVote(1, c.ip, c.options)
+			if c.conflict {
+				if !IsConflictError(err) {
+					t.Fatalf("expected conflict, got %v", err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			votes, err := GetPollVotes(nil, 1, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			AssertDeepEquals(t, votes, c.votes)
+		})
+	}
+}
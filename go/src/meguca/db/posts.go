@@ -8,7 +8,7 @@ import (
 	"fmt"
 	"meguca/auth"
 	"meguca/common"
-	"strconv"
+	"meguca/config"
 	"time"
 
 	"github.com/lib/pq"
@@ -37,6 +37,10 @@ type Post struct {
 	Password []byte
 	IP       string
 	UniqueID string
+	// Post token the post was created with. Used to authorize edits.
+	Token string
+	// Set, if a word filter held the post for moderator review
+	HoldReason string
 }
 
 // Thread is a template for writing new threads to the database
@@ -47,7 +51,7 @@ type Thread struct {
 	Subject, Board      string
 }
 
-// For decoding and encoding the tuple arrays we store links in.
+// For encoding and decoding post links.
 type linkRow common.Links
 
 func (l *linkRow) Scan(src interface{}) error {
@@ -60,81 +64,43 @@ func (l *linkRow) Scan(src interface{}) error {
 		*l = nil
 		return nil
 	default:
-		return fmt.Errorf("db: cannot convert %T to [][2]uint", src)
+		return fmt.Errorf("db: cannot convert %T to common.Links", src)
 	}
 }
 
-func (l *linkRow) scanBytes(src []byte) error {
-	length := len(src)
-	if length < 6 {
-		return errors.New("db: source too short")
+func (l *linkRow) scanBytes(data []byte) (err error) {
+	var bArr pq.ByteaArray
+	err = bArr.Scan(data)
+	if err != nil {
+		return
 	}
 
-	src = src[1 : length-1]
-
-	// Determine needed size and preallocate final array
-	commas := 0
-	for _, b := range src {
-		if b == ',' {
-			commas++
-		}
-	}
-	*l = make(linkRow, 0, (commas-1)/2+1)
-
-	var (
-		inner bool
-		next  [2]uint64
-		err   error
-		buf   = make([]byte, 0, 16)
-	)
-	for _, b := range src {
-		switch b {
-		case '{': // New tuple
-			inner = true
-			buf = buf[0:0]
-		case ',':
-			if inner { // End of first uint
-				next[0], err = strconv.ParseUint(string(buf), 10, 64)
-				if err != nil {
-					return err
-				}
-				buf = buf[0:0]
-			}
-		case '}': // End of tuple
-			next[1], err = strconv.ParseUint(string(buf), 10, 64)
-			if err != nil {
-				return err
-			}
-			*l = append(*l, next)
-		default:
-			buf = append(buf, b)
+	*l = make([]common.Link, len(bArr))
+	for i := range bArr {
+		err = (*l)[i].UnmarshalJSON(bArr[i])
+		if err != nil {
+			return
 		}
 	}
 
-	return nil
+	return
 }
 
 func (l linkRow) Value() (driver.Value, error) {
-	n := len(l)
-	if n == 0 {
+	if len(l) == 0 {
 		return nil, nil
 	}
 
-	b := make([]byte, 1, 16)
-	b[0] = '{'
-	for i, l := range l {
-		if i != 0 {
-			b = append(b, ',')
+	var strArr = make(pq.StringArray, len(l))
+	for i := range strArr {
+		s, err := l[i].MarshalJSON()
+		if err != nil {
+			return nil, err
 		}
-		b = append(b, '{')
-		b = strconv.AppendUint(b, l[0], 10)
-		b = append(b, ',')
-		b = strconv.AppendUint(b, l[1], 10)
-		b = append(b, '}')
+		strArr[i] = string(s)
 	}
-	b = append(b, '}')
 
-	return string(b), nil
+	return strArr.Value()
 }
 
 // For encoding and decoding command results.
@@ -197,6 +163,25 @@ func ValidateOP(id uint64, board string) (valid bool, err error) {
 	}
 	return
 }
+
+// ThreadState contains the flags, that restrict replying to a thread
+type ThreadState struct {
+	Archived, Locked bool
+}
+
+// Closed returns, if no new posts can be made in the thread
+func (s ThreadState) Closed() bool {
+	return s.Archived || s.Locked
+}
+
+// GetThreadState retrieves the archived and locked flags of a thread
+func GetThreadState(tx *sql.Tx, id uint64) (s ThreadState, err error) {
+	err = getStatement(tx, "get_thread_state").
+		QueryRow(id).
+		Scan(&s.Archived, &s.Locked)
+	return
+}
+
 func GetSmile(smileName string, board string) (s common.SmileCommon, err error) {
 	err = prepared["get_smile"].QueryRow(board, smileName).Scan(&s.Name, &s.ID, &s.SHA1, &s.Board)
 	if err == sql.ErrNoRows {
@@ -293,21 +278,102 @@ func InsertThread(tx *sql.Tx, p Post, subject string) (err error) {
 	if err != nil {
 		return
 	}
+	err = writePostAuth(tx, p)
+	if err != nil {
+		return
+	}
+	err = writePostLinks(tx, p.ID, p.Links)
+	if err != nil {
+		return
+	}
+	err = writeNotifications(tx, p)
+	if err != nil {
+		return
+	}
 	err = InsertFiles(tx, p)
 	return
 }
 
 // InsertPost inserts a post into an existing thread.
 func InsertPost(tx *sql.Tx, p Post) (err error) {
-	args := getPostCreationArgs(p)
+	args := append(getPostCreationArgs(p), p.Sage)
 	err = execPreparedTx(tx, "insert_post", args...)
 	if err != nil {
 		return
 	}
+	err = writePostAuth(tx, p)
+	if err != nil {
+		return
+	}
+	err = writePostLinks(tx, p.ID, p.Links)
+	if err != nil {
+		return
+	}
+	err = writeNotifications(tx, p)
+	if err != nil {
+		return
+	}
 	err = InsertFiles(tx, p)
 	return
 }
 
+// Record links of a post to look up backlinks of the linked posts
+func writePostLinks(tx *sql.Tx, id uint64, links common.Links) error {
+	if len(links) == 0 {
+		return nil
+	}
+	ids := make([]uint64, len(links))
+	for i, l := range links {
+		ids[i] = l.ID
+	}
+	_, err := getStatement(tx, "insert_post_links").Exec(id, pq.Array(ids))
+	return err
+}
+
+// GetBacklinks retrieves posts linking to any of the passed posts, keyed
+// by the linked post ID
+func GetBacklinks(tx *sql.Tx, ids []uint64) (
+	bls map[uint64]common.Links, err error,
+) {
+	r, err := getStatement(tx, "get_backlinks").Query(pq.Array(ids))
+	if err != nil {
+		return
+	}
+	defer r.Close()
+
+	bls = make(map[uint64]common.Links)
+	for r.Next() {
+		var (
+			target uint64
+			l      common.Link
+		)
+		err = r.Scan(&target, &l.ID, &l.OP, &l.Board)
+		if err != nil {
+			return
+		}
+		bls[target] = append(bls[target], l)
+	}
+	err = r.Err()
+	return
+}
+
+// Drop backlinks from mod-only boards other than the linked post's board.
+// Thread and post JSON is cached and shared between all clients, so these
+// can't be filtered per session.
+func filterBacklinks(links common.Links, board string) common.Links {
+	filtered := links[:0]
+	for _, l := range links {
+		if l.Board != board && config.IsModOnlyBoard(l.Board) {
+			continue
+		}
+		filtered = append(filtered, l)
+	}
+	if len(filtered) == 0 {
+		return nil
+	}
+	return filtered
+}
+
 // InsertUserReaction inserts a post's reaction with relation to post.
 func InsertUserReaction(ss *auth.Session, reactionID uint64) (err error) {
 	var userID *string
@@ -434,6 +500,43 @@ func CanCreateThread(ip string) bool {
 	return passed.Minutes() >= 1.0
 }
 
+// IsNewIP returns, if the IP has not posted before the given time
+func IsNewIP(tx *sql.Tx, ip string, before time.Time) (bool, error) {
+	var unix int64
+	err := getStatement(tx, "get_first_post_time_by_ip").QueryRow(ip).
+		Scan(&unix)
+	switch err {
+	case nil:
+		return unix > before.Unix(), nil
+	case sql.ErrNoRows:
+		return true, nil
+	default:
+		return false, err
+	}
+}
+
+// CountDuplicateBodies returns the number of posts with the same body in
+// other threads since the given time
+func CountDuplicateBodies(tx *sql.Tx, body string, op uint64, since time.Time) (
+	n int, err error,
+) {
+	err = getStatement(tx, "count_duplicate_bodies").
+		QueryRow(body, op, since.Unix()).
+		Scan(&n)
+	return
+}
+
+// CountRecentFiles returns the number of posts with any of the files
+// since the given time
+func CountRecentFiles(tx *sql.Tx, sha1s []string, since time.Time) (
+	n int, err error,
+) {
+	err = getStatement(tx, "count_recent_files").
+		QueryRow(pq.StringArray(sha1s), since.Unix()).
+		Scan(&n)
+	return
+}
+
 func CanCreatePost(ip string) bool {
 	var unix int64
 	err := prepared["get_last_post_time_by_ip"].QueryRow(ip).Scan(&unix)
@@ -3,6 +3,7 @@ package db
 import (
 	"meguca/common"
 	"meguca/config"
+	. "meguca/test"
 	"testing"
 	"time"
 )
@@ -36,6 +37,79 @@ func TestValidateOp(t *testing.T) {
 	}
 }
 
+func TestInsertPostBump(t *testing.T) {
+	assertTableClear(t, "boards")
+	c := config.BoardConfig{
+		BoardPublic: config.BoardPublic{ID: "a"},
+		BumpLimit:   2,
+	}
+	if err := WriteBoard(nil, c); err != nil {
+		t.Fatal(err)
+	}
+	thread := Post{
+		StandalonePost: common.StandalonePost{
+			Post: common.Post{
+				ID:   1,
+				Time: time.Now().Unix(),
+			},
+			OP:    1,
+			Board: "a",
+		},
+	}
+	if err := InsertThread(nil, thread, "subject"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Run sequentially, as all cases reply to the same thread
+	cases := [...]struct {
+		name    string
+		postCtr int
+		sage    bool
+		bumped  bool
+	}{
+		{"reply", 1, false, true},
+		{"sage", 1, true, false},
+		{"at bump limit", 2, false, true},
+		{"past bump limit", 3, false, false},
+		{"sage past bump limit", 3, true, false},
+	}
+
+	for i, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assertExec(t,
+				`update threads set postCtr = $1, bumpTime = 0 where id = 1`,
+				c.postCtr)
+
+			id := uint64(i + 2)
+			p := thread
+			p.ID = id
+			p.Sage = c.sage
+			if err := InsertPost(nil, p); err != nil {
+				t.Fatal(err)
+			}
+
+			var bumpTime int64
+			err := db.QueryRow(`select bumpTime from threads where id = 1`).
+				Scan(&bumpTime)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bumped := bumpTime != 0; bumped != c.bumped {
+				t.Fatalf("unexpected bump: %t : %t", c.bumped, bumped)
+			}
+
+			// Sage is never forced on replies past the bump limit
+			post, err := GetPost(id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if post.Sage != c.sage {
+				t.Fatalf("unexpected sage: %t : %t", c.sage, post.Sage)
+			}
+		})
+	}
+}
+
 func writeSampleBoard(t *testing.T) {
 	b := BoardConfigs{
 		BoardConfigs: config.BoardConfigs{
@@ -65,3 +139,79 @@ func writeSampleThread(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestGetBacklinks(t *testing.T) {
+	writeMoveSample(t)
+	p := Post{
+		StandalonePost: common.StandalonePost{
+			Post: common.Post{
+				ID:   5,
+				Time: time.Now().Unix(),
+				Links: common.Links{
+					{ID: 1, OP: 1, Board: "a"},
+					{ID: 2, OP: 1, Board: "a"},
+				},
+			},
+			OP:    1,
+			Board: "a",
+		},
+	}
+	if err := InsertPost(nil, p); err != nil {
+		t.Fatal(err)
+	}
+	// Deleted posts are not listed as backlinks
+	p.ID = 6
+	if err := InsertPost(nil, p); err != nil {
+		t.Fatal(err)
+	}
+	assertExec(t, `update posts set deleted = true where id = 6`)
+
+	bls, err := GetBacklinks(nil, []uint64{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertDeepEquals(t, bls, map[uint64]common.Links{
+		1: {{ID: 5, OP: 1, Board: "a"}},
+		2: {{ID: 4, OP: 3, Board: "a"}, {ID: 5, OP: 1, Board: "a"}},
+	})
+
+	post, err := GetPost(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertDeepEquals(t, post.Backlinks, bls[2])
+}
+
+func TestFilterBacklinks(t *testing.T) {
+	err := config.SetBoardConfig(config.BoardConfig{
+		BoardPublic: config.BoardPublic{ID: "m"},
+		ModOnly:     true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer config.RemoveBoard("m")
+
+	links := common.Links{
+		{ID: 1, OP: 1, Board: "a"},
+		{ID: 2, OP: 2, Board: "m"},
+	}
+	cases := [...]struct {
+		name, board string
+		links, std  common.Links
+	}{
+		{"no backlinks", "a", nil, nil},
+		{"other board", "a", links, links[:1]},
+		{"same mod-only board", "m", links, links},
+		{"only mod-only links", "a", links[1:], nil},
+	}
+
+	for i := range cases {
+		c := cases[i]
+		t.Run(c.name, func(t *testing.T) {
+			// filterBacklinks reuses the backing array
+			links := append(common.Links(nil), c.links...)
+			AssertDeepEquals(t, filterBacklinks(links, c.board), c.std)
+		})
+	}
+}
@@ -17,7 +17,7 @@ type threadScanner struct {
 
 func (t *threadScanner) ScanArgs() []interface{} {
 	return []interface{}{
-		&t.Sticky, &t.Board,
+		&t.Sticky, &t.Archived, &t.Locked, &t.Board,
 		&t.PostCtr, &t.ImageCtr,
 		&t.ReplyTime, &t.BumpTime,
 		&t.Subject,
@@ -34,12 +34,14 @@ type postScanner struct {
 	userID   sql.NullString
 	userName sql.NullString
 	settings sql.NullString
+	sage     sql.NullBool
+	trip     sql.NullString
 	links    linkRow
 	commands commandRow
 }
 
 func (p *postScanner) ScanArgs() []interface{} {
-	return []interface{}{&p.ID, &p.Time, &p.auth, &p.userID, &p.userName, &p.Body, &p.links, &p.commands, &p.settings}
+	return []interface{}{&p.ID, &p.Time, &p.auth, &p.userID, &p.userName, &p.Body, &p.links, &p.commands, &p.settings, &p.sage, &p.trip}
 }
 
 type AccountSettings struct {
@@ -78,7 +80,9 @@ func (p *postScanner) Val() common.Post {
 	p.UserID = p.userID.String
 	p.UserName = p.userName.String
 	p.UserColor = UserColor
-	p.Links = [][2]uint64(p.links)
+	p.Sage = p.sage.Bool
+	p.Trip = p.trip.String
+	p.Links = common.Links(p.links)
 	p.Commands = []common.Command(p.commands)
 	// p.Settings = p.settings.String
 	return p.Post
@@ -236,6 +240,15 @@ func GetBoardCatalog(board string) (common.Board, error) {
 	return scanCatalog(r)
 }
 
+// GetBoardArchive retrieves all archived OPs of a single board.
+func GetBoardArchive(board string) (common.Board, error) {
+	r, err := prepared["get_archive"].Query(board)
+	if err != nil {
+		return nil, err
+	}
+	return scanCatalog(r)
+}
+
 func findReactionsInList(re common.Reacts, id uint64) (r common.Reacts, err error) {
 	for _, v := range re {
 		if v.PostID == id {
@@ -349,6 +362,20 @@ func GetThread(id uint64, lastN int) (t common.Thread, err error) {
 		}
 	}
 	err = r2.Err()
+	if err != nil {
+		return
+	}
+
+	// Fill posts backlinks.
+	bls, err := GetBacklinks(tx, postIds)
+	if err != nil {
+		return
+	}
+	for target, links := range bls {
+		postsById[target].Backlinks = filterBacklinks(links, t.Board)
+	}
+
+	t.Poll, err = GetPoll(tx, id)
 	return
 }
 
@@ -536,9 +563,17 @@ func GetPost(id uint64) (p common.StandalonePost, err error) {
 		img := fs.Val()
 		p.Files = append(p.Files, img)
 	}
-
 	err = r.Err()
+	if err != nil {
+		return
+	}
 
+	// Get post backlinks.
+	bls, err := GetBacklinks(tx, []uint64{id})
+	if err != nil {
+		return
+	}
+	p.Backlinks = filterBacklinks(bls[id], p.Board)
 	return
 }
 
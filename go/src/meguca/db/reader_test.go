@@ -65,7 +65,7 @@ func TestReader(t *testing.T) {
 			StandalonePost: common.StandalonePost{
 				Post: common.Post{
 					ID:    3,
-					Links: [][2]uint64{{1, 1}},
+					Links: common.Links{{ID: 1, OP: 1, Board: "a"}},
 					Commands: []common.Command{
 						{
 							Type: common.Flip,
@@ -134,7 +134,7 @@ func testGetPost(t *testing.T) {
 	std := common.StandalonePost{
 		Post: common.Post{
 			ID:    3,
-			Links: [][2]uint64{{1, 1}},
+			Links: common.Links{{ID: 1, OP: 1, Board: "a"}},
 			Commands: []common.Command{
 				{
 					Type: common.Flip,
@@ -159,7 +159,7 @@ func testGetAllBoard(t *testing.T) {
 		{
 			Post: common.Post{
 				ID:    3,
-				Links: [][2]uint64{{1, 1}},
+				Links: common.Links{{ID: 1, OP: 1, Board: "a"}},
 				Commands: []common.Command{
 					{
 						Type: common.Flip,
@@ -220,7 +220,7 @@ func testGetBoard(t *testing.T) {
 				{
 					Post: common.Post{
 						ID:    3,
-						Links: [][2]uint64{{1, 1}},
+						Links: common.Links{{ID: 1, OP: 1, Board: "a"}},
 						Commands: []common.Command{
 							{
 								Type: common.Flip,
@@ -313,7 +313,7 @@ func testGetThread(t *testing.T) {
 				PostCtr:   1,
 				Post: common.Post{
 					ID:    3,
-					Links: [][2]uint64{{1, 1}},
+					Links: common.Links{{ID: 1, OP: 1, Board: "a"}},
 					Commands: []common.Command{
 						{
 							Type: common.Flip,
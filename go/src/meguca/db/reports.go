@@ -0,0 +1,92 @@
+// User reports about rule-breaking posts
+
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"meguca/auth"
+	"meguca/common"
+
+	"github.com/lib/pq"
+)
+
+// InsertReport writes a user report about a post
+func InsertReport(id uint64, board, reason, ip string) error {
+	return execPrepared("insert_report", id, board, reason, ip,
+		time.Now().Unix())
+}
+
+// HoldPost hides a post, when a word filter holds it for review, and adds
+// it to the report queue of its board on behalf of the server
+func HoldPost(tx *sql.Tx, id uint64, board, reason string) (err error) {
+	_, err = getStatement(tx, "hold_post").Exec(id)
+	if err != nil {
+		return
+	}
+	_, err = getStatement(tx, "insert_report").
+		Exec(id, board, reason, nil, time.Now().Unix())
+	return
+}
+
+// ApprovePost publishes a post held by a word filter. Returns
+// sql.ErrNoRows, if the post is not held.
+func ApprovePost(id uint64, by string) error {
+	return restorePost("approve_post", id, by)
+}
+
+// CanReport returns, if the IP has not exceeded the report rate limit
+func CanReport(ip string) (can bool, err error) {
+	var n int
+	since := time.Now().Add(-common.ReportInterval * time.Minute).Unix()
+	err = prepared["count_reports_by_ip"].QueryRow(ip, since).Scan(&n)
+	can = n < common.MaxReportsPerInterval
+	return
+}
+
+// GetReports retrieves all open reports of a board, oldest first
+func GetReports(board string) (reports auth.Reports, err error) {
+	reports = make(auth.Reports, 0)
+	r, err := prepared["get_reports"].Query(board)
+	if err != nil {
+		return
+	}
+	defer r.Close()
+	for r.Next() {
+		var rep auth.Report
+		err = r.Scan(&rep.ID, &rep.PostID, &rep.OP, &rep.Board, &rep.Reason,
+			&rep.Created)
+		if err != nil {
+			return
+		}
+		reports = append(reports, rep)
+	}
+	err = r.Err()
+	return
+}
+
+// GetReportedPosts retrieves IDs of posts targeted by open reports of a
+// board
+func GetReportedPosts(board string, ids []uint64) (posts []uint64, err error) {
+	r, err := prepared["get_reported_posts"].Query(board, pq.Array(ids))
+	if err != nil {
+		return
+	}
+	defer r.Close()
+	for r.Next() {
+		var id uint64
+		err = r.Scan(&id)
+		if err != nil {
+			return
+		}
+		posts = append(posts, id)
+	}
+	err = r.Err()
+	return
+}
+
+// ResolveReports closes all open reports of a board about the passed posts
+func ResolveReports(board string, posts []uint64, by string) error {
+	return execPrepared("resolve_reports", board, pq.Array(posts), by)
+}
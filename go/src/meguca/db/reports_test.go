@@ -0,0 +1,193 @@
+package db
+
+import (
+	"database/sql"
+	"meguca/common"
+	. "meguca/test"
+	"testing"
+	"time"
+)
+
+func assertCanReport(t *testing.T, ip string, std bool) {
+	t.Helper()
+	can, err := CanReport(ip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if can != std {
+		t.Fatalf("can report: expected %v, got %v", std, can)
+	}
+}
+
+func TestCanReport(t *testing.T) {
+	writeMoveSample(t)
+
+	// An IP can report each post only once, so report separate posts
+	const ip = "::1"
+	for i := 0; i < common.MaxReportsPerInterval; i++ {
+		assertCanReport(t, ip, true)
+		id := uint64(10 + i)
+		err := InsertPost(nil, Post{
+			StandalonePost: common.StandalonePost{
+				Post: common.Post{
+					ID:   id,
+					Time: time.Now().Unix(),
+					Body: "foo",
+				},
+				OP:    1,
+				Board: "a",
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := InsertReport(id, "a", "spam", ip); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assertCanReport(t, ip, false)
+	assertCanReport(t, "::2", true)
+
+	// Reports older than the interval don't count
+	old := time.Now().Add(-(common.ReportInterval + 1) * time.Minute)
+	assertExec(t, `update reports set created = $1 where ip = $2`,
+		old.Unix(), ip)
+	assertCanReport(t, ip, true)
+}
+
+func TestHoldPost(t *testing.T) {
+	writeMoveSample(t)
+
+	if err := HoldPost(nil, 2, "a", "Word filter: foo"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetPost(2); err != sql.ErrNoRows {
+		t.Fatalf("held post not hidden: %v", err)
+	}
+	reports, err := GetReports("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 1 || reports[0].PostID != 2 {
+		t.Fatalf("unexpected reports: %v", reports)
+	}
+
+	// Held posts are neither listed nor restored as deleted posts
+	deleted, err := GetDeletedPosts("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 0 {
+		t.Fatalf("held post listed as deleted: %v", deleted)
+	}
+	if err := UndeletePost(2, "admin"); err != sql.ErrNoRows {
+		t.Fatalf("held post undeleted: %v", err)
+	}
+
+	// Only held posts can be approved, not ones deleted without a moderator
+	// before posts could be held
+	assertExec(t, `update posts set deleted = true where id = 4`)
+	if err := ApprovePost(4, "admin"); err != sql.ErrNoRows {
+		t.Fatalf("approved post not held: %v", err)
+	}
+	if err := ApprovePost(2, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetPost(2); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetReportsSkipsDeleted(t *testing.T) {
+	writeMoveSample(t)
+
+	for _, id := range [...]uint64{2, 4} {
+		if err := InsertReport(id, "a", "spam", "::1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := DeletePost(4, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	reports, err := GetReports("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 1 || reports[0].PostID != 2 {
+		t.Fatalf("unexpected reports: %v", reports)
+	}
+}
+
+func TestResolveReports(t *testing.T) {
+	cases := [...]struct {
+		name   string
+		delete bool
+	}{
+		{"dismiss", false},
+		{"delete and resolve", true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			writeMoveSample(t)
+
+			for _, r := range [...]struct {
+				id uint64
+				ip string
+			}{
+				{2, "::1"},
+				{2, "::2"},
+				{4, "::1"},
+			} {
+				if err := InsertReport(r.id, "a", "spam", r.ip); err != nil {
+					t.Fatal(err)
+				}
+			}
+			reports, err := GetReports("a")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Resolving one report closes all reports about the same post
+			posts, err := GetReportedPosts("a", []uint64{reports[0].ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			AssertDeepEquals(t, posts, []uint64{2})
+			if c.delete {
+				if err := DeletePost(2, "admin"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := ResolveReports("a", posts, "admin"); err != nil {
+				t.Fatal(err)
+			}
+
+			// Reports of deleted posts are not listed, so count them directly
+			var open int
+			err = db.QueryRow(`select count(*) from reports where not resolved`).
+				Scan(&open)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if open != 1 {
+				t.Fatalf("expected 1 open report, got %d", open)
+			}
+
+			reports, err = GetReports("a")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(reports) != 1 || reports[0].PostID != 4 {
+				t.Fatalf("unexpected reports: %v", reports)
+			}
+			_, err = GetPost(2)
+			switch {
+			case c.delete && err != sql.ErrNoRows:
+				t.Fatalf("post not deleted: %v", err)
+			case !c.delete && err != nil:
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+// Full-text post search
+
+package db
+
+import (
+	"meguca/common"
+
+	"github.com/lib/pq"
+)
+
+// SearchParams filters the full-text post search
+type SearchParams struct {
+	Query  string
+	Boards []string
+	// Unix time bounds, 0 for unbounded
+	From, To int64
+	HasFiles bool
+	// Account ID of the post author
+	Author string
+	Page   int
+}
+
+// SearchPosts returns a page of posts matching the search parameters,
+// newest first. more is true, if there are further pages.
+func SearchPosts(p SearchParams) (
+	res []common.SearchResult, more bool, err error,
+) {
+	var from, to *int64
+	if p.From != 0 {
+		from = &p.From
+	}
+	if p.To != 0 {
+		to = &p.To
+	}
+	var author *string
+	if p.Author != "" {
+		author = &p.Author
+	}
+	limit := common.SearchResultsPerPage + 1
+	offset := p.Page * common.SearchResultsPerPage
+
+	r, err := prepared["search_posts"].Query(
+		p.Query, pq.StringArray(p.Boards), from, to, p.HasFiles, author,
+		limit, offset,
+	)
+	if err != nil {
+		return
+	}
+	defer r.Close()
+
+	var ps postScanner
+	res = make([]common.SearchResult, 0, limit)
+	for r.Next() {
+		var s common.SearchResult
+		args := append(ps.ScanArgs(), &s.OP, &s.Board, &s.Subject)
+		err = r.Scan(args...)
+		if err != nil {
+			return
+		}
+		s.Post = ps.Val()
+		res = append(res, s)
+	}
+	err = r.Err()
+	if err != nil {
+		return
+	}
+	if len(res) == limit {
+		more = true
+		res = res[:common.SearchResultsPerPage]
+	}
+	if len(res) == 0 {
+		return
+	}
+
+	// Fill posts files.
+	ids := make([]uint64, len(res))
+	byID := make(map[uint64]*common.SearchResult, len(res))
+	for i := range res {
+		ids[i] = res[i].ID
+		byID[res[i].ID] = &res[i]
+	}
+	r2, err := prepared["get_abbrev_thread_files"].Query(pq.Array(ids))
+	if err != nil {
+		return
+	}
+	defer r2.Close()
+
+	var fs fileScanner
+	var pID uint64
+	args := append([]interface{}{&pID}, fs.ScanArgs()...)
+	for r2.Next() {
+		err = r2.Scan(args...)
+		if err != nil {
+			return
+		}
+		if s, ok := byID[pID]; ok {
+			s.Files = append(s.Files, fs.Val())
+		}
+	}
+	err = r2.Err()
+	return
+}
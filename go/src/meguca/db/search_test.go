@@ -0,0 +1,156 @@
+package db
+
+import (
+	"meguca/assets"
+	"meguca/common"
+	"meguca/config"
+	. "meguca/test"
+	"testing"
+	"time"
+)
+
+func TestSearchPosts(t *testing.T) {
+	assertTableClear(t, "boards", "accounts", "images")
+	if err := RegisterAccount("alice", []byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	writeSampleImage(t)
+	for _, id := range [...]string{"a", "b"} {
+		c := config.BoardConfig{
+			BoardPublic: config.BoardPublic{ID: id},
+		}
+		if err := WriteBoard(nil, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	const day = 24 * 60 * 60
+	now := time.Now().Unix()
+	post := func(id, op uint64, board, body string, created int64) Post {
+		return Post{
+			StandalonePost: common.StandalonePost{
+				Post: common.Post{
+					ID:   id,
+					Time: created,
+					Body: body,
+				},
+				OP:    op,
+				Board: board,
+			},
+		}
+	}
+	err := InsertThread(nil, post(1, 1, "a", "first", now), "news")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = InsertThread(nil, post(4, 4, "b", "foo", now), "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	byAlice := post(2, 1, "a", "foo bar", now)
+	byAlice.UserID = "alice"
+	withFile := post(3, 1, "a", "foo baz", now-10*day)
+	withFile.Files = []*common.Image{
+		{ImageCommon: assets.StdJPEG.ImageCommon},
+	}
+	for _, p := range [...]Post{
+		byAlice,
+		withFile,
+		post(5, 1, "a", "foo", now),
+	} {
+		if err := InsertPost(nil, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assertExec(t, `update posts set deleted = true where id = 5`)
+
+	both := []string{"a", "b"}
+	cases := [...]struct {
+		name string
+		p    SearchParams
+		ids  []uint64
+	}{
+		{
+			name: "body match",
+			p:    SearchParams{Query: "foo", Boards: both},
+			ids:  []uint64{4, 3, 2},
+		},
+		{
+			name: "board filter",
+			p:    SearchParams{Query: "foo", Boards: []string{"a"}},
+			ids:  []uint64{3, 2},
+		},
+		{
+			name: "thread subject",
+			p:    SearchParams{Query: "news", Boards: both},
+			ids:  []uint64{1},
+		},
+		{
+			name: "no match",
+			p:    SearchParams{Query: "qux", Boards: both},
+		},
+		{
+			name: "no boards",
+			p:    SearchParams{Query: "foo"},
+		},
+		{
+			name: "from date",
+			p: SearchParams{
+				Query:  "foo",
+				Boards: both,
+				From:   now - day,
+			},
+			ids: []uint64{4, 2},
+		},
+		{
+			name: "to date",
+			p: SearchParams{
+				Query:  "foo",
+				Boards: both,
+				To:     now - day,
+			},
+			ids: []uint64{3},
+		},
+		{
+			name: "has files",
+			p:    SearchParams{Query: "foo", Boards: both, HasFiles: true},
+			ids:  []uint64{3},
+		},
+		{
+			name: "author",
+			p:    SearchParams{Query: "foo", Boards: both, Author: "alice"},
+			ids:  []uint64{2},
+		},
+		{
+			name: "past last page",
+			p:    SearchParams{Query: "foo", Boards: both, Page: 1},
+		},
+	}
+
+	for i := range cases {
+		c := cases[i]
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			res, more, err := SearchPosts(c.p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if more {
+				t.Error("unexpected next page")
+			}
+			ids := make([]uint64, 0, len(res))
+			for _, r := range res {
+				ids = append(ids, r.ID)
+				if files := len(r.Files); (r.ID == 3) != (files == 1) {
+					t.Errorf("unexpected file count of post %d: %d",
+						r.ID, files)
+				}
+			}
+			if c.ids == nil {
+				c.ids = []uint64{}
+			}
+			AssertDeepEquals(t, ids, c.ids)
+		})
+	}
+}
@@ -1,9 +1,16 @@
 WITH files AS (
-  SELECT count(*) AS cnt FROM post_files WHERE post_id = $1
+  SELECT count(*) AS cnt, array_agg(file_hash) AS hashes
+    FROM post_files WHERE post_id = $1
 )
 
-DELETE FROM posts USING files WHERE id = $1
+UPDATE posts
+  SET deleted = true,
+    deleted_at = floor(extract(epoch from now())),
+    deleted_by = $2
+  FROM files
+  WHERE id = $1 AND deleted IS NOT TRUE
 
 RETURNING
-  log_moderation(2::smallint, board, id, $2),
+  log_moderation_details($3::smallint, board, id, $2, null, null, body,
+    files.hashes),
   bump_thread(op, false, true, false, files.cnt)
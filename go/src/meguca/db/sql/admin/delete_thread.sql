@@ -1,3 +1,6 @@
 delete from threads
   where id = $1
-  returning log_moderation(5::smallint, board, id, $2::varchar(20))
+  returning log_moderation_details(5::smallint, board, id, $2::varchar(20),
+    null, null,
+    (select body from posts where id = $1),
+    (select array_agg(file_hash) from post_files where post_id = $1))
@@ -1,4 +1,6 @@
 select ip, board, forPost, reason, by, expires
   from bans
-  where ip = $1 and board = $2 and expires >= now()
+  where board = $2 and expires >= now()
+    and (ip >>= $1::inet or (scope = 2 and unique_id = $3))
+  order by masklen(ip) desc
   limit 1
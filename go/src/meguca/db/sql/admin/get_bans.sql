@@ -1,3 +1,3 @@
-SELECT board, ip, forPost, by, expires, reason FROM bans
+SELECT board, ip, forPost, by, expires, reason, unique_id, scope FROM bans
 WHERE board = ANY($1)
 ORDER BY expires DESC
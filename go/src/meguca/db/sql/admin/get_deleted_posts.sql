@@ -0,0 +1,10 @@
+select p.id, p.op, p.board, p.time, p.body, p.deleted_at, p.deleted_by,
+    array(
+      select pf.file_hash from post_files pf
+        where pf.post_id = p.id
+        order by pf.id
+    )
+  from posts p
+  where p.board = $1 and p.deleted and not p.held
+  order by p.deleted_at desc
+  limit $2
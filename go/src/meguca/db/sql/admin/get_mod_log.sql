@@ -1,3 +1,9 @@
-SELECT board, id, type, by, created FROM mod_log
+SELECT board, id, type, by, created, reason, duration, post_body, post_files,
+  post_ids
+FROM mod_log
 WHERE board = ANY($1)
+  AND ($2::varchar(20) = '' OR by = $2)
+  AND (cardinality($3::smallint[]) = 0 OR type = ANY($3))
+  AND ($4::timestamp IS NULL OR created >= $4)
+  AND ($5::timestamp IS NULL OR created < $5)
 ORDER BY created DESC
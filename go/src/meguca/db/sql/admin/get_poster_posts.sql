@@ -0,0 +1,14 @@
+select p.id, p.op
+  from posts p,
+    (select ip, unique_id from posts where id = $1 and board = $2) t
+  where p.board = $2
+    and p.deleted is not true
+    and (
+      p.id = $1
+      or case
+        when $3 and t.unique_id is not null then p.unique_id = t.unique_id
+        else p.ip = t.ip
+      end
+    )
+    and ($4::bigint = 0 or p.time >= $4)
+  order by p.id
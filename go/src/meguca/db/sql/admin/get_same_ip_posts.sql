@@ -5,3 +5,4 @@ select id from posts
         where id = $1 and board = $2
     )
     and board = $2
+    and deleted is not true
@@ -1,2 +1,2 @@
-select ip, board from bans
+select ip, board, scope, unique_id from bans
   where expires >= now()
@@ -0,0 +1,2 @@
+insert into mod_log (type, board, id, by, post_ids)
+  values (17, $1, $2, $3, $4)
@@ -0,0 +1,12 @@
+with src as (
+  delete from threads
+    where id = $1
+    returning postCtr, imageCtr, log_moderation(13::smallint, board, id, $3::varchar(20))
+)
+update threads t
+  set postCtr = t.postCtr + src.postCtr,
+    imageCtr = t.imageCtr + src.imageCtr,
+    replyTime = floor(extract(epoch from now()))
+  from src
+  where t.id = $2
+  returning log_moderation(13::smallint, t.board, $1, $3::varchar(20))
@@ -0,0 +1,4 @@
+update posts
+  set op = $2, board = $3
+  where op = $1
+  returning id
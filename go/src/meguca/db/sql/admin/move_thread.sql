@@ -0,0 +1,6 @@
+update threads
+  set board = $2, replyTime = floor(extract(epoch from now()))
+  where id = $1
+  returning
+    log_moderation(12::smallint, $4::text, id, $3::varchar(20)),
+    log_moderation(12::smallint, board, id, $3::varchar(20))
@@ -0,0 +1,12 @@
+WITH files AS (
+  SELECT count(*) AS cnt FROM post_files WHERE post_id = $1
+)
+
+UPDATE posts
+  SET deleted = true,
+    deleted_at = floor(extract(epoch from now())),
+    deleted_by = $2
+  FROM files
+  WHERE id = $1 AND deleted IS NOT TRUE
+
+RETURNING bump_thread(op, false, true, false, files.cnt)
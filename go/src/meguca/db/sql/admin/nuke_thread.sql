@@ -0,0 +1,10 @@
+-- Delete a thread with all of its posts and return the IDs of the posts, that
+-- were not deleted yet
+with thread_posts as (
+  select id from posts
+    where op = $1 and deleted is not true
+), deleted as (
+  delete from threads
+    where id = $1
+)
+select id from thread_posts
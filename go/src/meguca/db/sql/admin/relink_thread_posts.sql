@@ -0,0 +1,18 @@
+-- Rewrite links to posts of thread $1 with their current OP and board,
+-- after the posts were moved or merged
+update posts p
+  set links = array(
+    select case
+        when t.id is null then l.link
+        else json_build_array(t.id, t.op, t.board)
+      end
+      from unnest(p.links) with ordinality as l(link, n)
+      left join posts t on t.id = (l.link->>0)::bigint and t.op = $1
+      order by l.n
+  )
+  where p.id in (
+    select pl.source
+      from post_links pl
+      join posts t on t.id = pl.target
+      where t.op = $1
+  )
@@ -0,0 +1,6 @@
+update threads
+  set locked = $2
+  where id = $1
+  returning
+    log_moderation((case when $2 then 10 else 11 end)::smallint, board, id, $3::varchar(20)),
+    bump_thread($1, false, false, false, 0)
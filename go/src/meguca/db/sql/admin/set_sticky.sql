@@ -1,4 +1,6 @@
 update threads
   set sticky = $2
   where id = $1
-  returning bump_thread($1, false, false, false, 0)
+  returning
+    log_moderation((case when $2 then 8 else 9 end)::smallint, board, id, $3::varchar(20)),
+    bump_thread($1, false, false, false, 0)
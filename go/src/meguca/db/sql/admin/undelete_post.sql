@@ -0,0 +1,13 @@
+WITH files AS (
+  SELECT count(*) AS cnt FROM post_files WHERE post_id = $1
+)
+
+UPDATE posts
+  SET deleted = false, deleted_at = NULL, deleted_by = NULL
+  FROM files
+  -- Held posts are only released by approving them
+  WHERE id = $1 AND deleted AND NOT held
+
+RETURNING
+  log_moderation(16::smallint, board, id, $2),
+  bump_thread(op, true, false, false, files.cnt)
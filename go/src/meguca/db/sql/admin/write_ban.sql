@@ -1,4 +1,6 @@
-INSERT INTO bans (board, ip, forPost, by, expires, reason, unique_id)
-VALUES           ($1,    $2, $3,      $4, $5,      $6,     $7)
+INSERT INTO bans (board, ip, forPost, by, expires, reason, unique_id, scope)
+VALUES           ($1,    $2, $3,      $4, $5,      $6,     $7,        $8)
 ON CONFLICT DO NOTHING
-RETURNING log_moderation(0::smallint, $1, $3, $4)
+RETURNING log_moderation_details(0::smallint, $1, $3, $4, $6, $9,
+  (SELECT body FROM posts WHERE id = $3),
+  (SELECT array_agg(file_hash) FROM post_files WHERE post_id = $3))
@@ -0,0 +1,3 @@
+select id, board, ban_id, text, created, resolved, accepted
+  from ban_appeals
+  where board = $1 and ip = $2 and ban_id = $3
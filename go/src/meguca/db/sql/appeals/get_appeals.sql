@@ -0,0 +1,6 @@
+select a.id, a.board, a.ban_id, b.reason, a.text, a.created, a.resolved,
+    a.accepted
+  from ban_appeals a
+  join bans b on b.board = a.board and b.ip = a.ip and b.forPost = a.ban_id
+  where a.board = $1 and not a.resolved
+  order by a.created
@@ -0,0 +1,2 @@
+insert into ban_appeals (board, ip, ban_id, text, created)
+  values ($1, $2, $3, $4, $5)
@@ -0,0 +1,9 @@
+update ban_appeals
+  set resolved = true, accepted = $3, resolved_by = $4
+  where board = $1 and id = $2 and not resolved
+  returning
+    ban_id,
+    log_moderation(
+      case when $3 then 14 else 15 end::smallint,
+      $1::text, ban_id, $4::varchar(20)
+    )
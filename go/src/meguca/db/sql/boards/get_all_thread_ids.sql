@@ -1,5 +1,5 @@
 select t.id from threads as t
   inner join boards as b
     on b.id = t.board
-  where NOT b.modOnly
+  where NOT b.modOnly and NOT t.archived
   order by bumpTime desc
@@ -10,7 +10,10 @@ CREATE OR REPLACE FUNCTION bump_thread(
     replyTime = floor(extract(epoch from now())),
 
     bumpTime = CASE
-      WHEN bump AND postCtr <= 500 THEN floor(extract(epoch from now()))
+      WHEN bump AND postCtr <= coalesce(
+        nullif((b.settings->>'bumpLimit')::bigint, 0),
+        500
+      ) THEN floor(extract(epoch from now()))
       ELSE bumpTime
     END,
 
@@ -26,6 +29,7 @@ CREATE OR REPLACE FUNCTION bump_thread(
       ELSE imageCtr
     END
 
-  WHERE id = bump_thread.id;
+  FROM boards b
+  WHERE threads.id = bump_thread.id AND b.id = threads.board;
 
 $$ LANGUAGE SQL;
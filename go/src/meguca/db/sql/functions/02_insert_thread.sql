@@ -8,7 +8,7 @@ CREATE OR REPLACE FUNCTION insert_thread(
   body text,
   ip inet,
   unique_id text,
-  links bigint[][2],
+  links json[],
   commands json[],
   file_cnt bigint,
   subject varchar(100)
@@ -0,0 +1,14 @@
+create or replace function log_moderation_details(
+  type smallint,
+  board text,
+  id bigint,
+  by varchar(20),
+  reason text,
+  duration bigint,
+  post_body text,
+  post_files char(40)[]
+) returns void as $$
+  insert into mod_log (type, board, id, by, reason, duration, post_body,
+      post_files)
+    values(type, board, id, by, reason, duration, post_body, post_files);
+$$ language sql;
@@ -30,18 +30,30 @@ create table bans (
   unique_id text,
   reason text not null,
   expires timestamp not null,
+  -- 0: IP, 1: network prefix in ip, 2: unique_id and IP
+  scope smallint not null default 0,
   primary key (ip, board)
 );
+create index bans_unique_id on bans (unique_id) where scope = 2;
 
 create table mod_log (
   type smallint not null,
   board text not null,
   id bigint not null,
   by varchar(20) not null,
-  created timestamp default (now() at time zone 'utc')
+  created timestamp default (now() at time zone 'utc'),
+  -- Ban reason and duration in seconds
+  reason text,
+  duration bigint,
+  -- Snapshot of the affected post
+  post_body text,
+  post_files char(40)[],
+  -- All posts affected by bulk actions
+  post_ids bigint[]
 );
 create index mod_log_board on mod_log (board);
 create index mod_log_created on mod_log (created);
+create index mod_log_by on mod_log (by);
 
 create table images (
   apng boolean not null,
@@ -91,6 +103,8 @@ create sequence post_id;
 
 create table threads (
   sticky boolean default false,
+  archived boolean not null default false,
+  locked boolean not null default false,
   board text not null references boards on delete cascade,
   id bigint primary key,
   postCtr bigint not null,
@@ -103,6 +117,8 @@ create index threads_board on threads (board);
 create index bumpTime on threads (bumpTime);
 create index replyTime on threads (replyTime);
 create index sticky on threads (sticky);
+create index threads_archived on threads (archived);
+create index threads_subject_fts on threads using gin (to_tsvector('simple', subject));
 
 create table smiles (
   name text not null,
@@ -136,14 +152,102 @@ create table posts (
   body text not null,
   password bytea,
   ip inet,
-  links bigint[][2],
-  commands json[]
+  links json[],
+  commands json[],
+  token char(20),
+  -- Set together with deleted by moderators
+  deleted_at bigint,
+  deleted_by varchar(20),
+  -- Hidden by a word filter until a moderator approves it
+  held boolean not null default false
 );
 create index op on posts (op);
 create index image on posts (SHA1);
 create index editing on posts (editing);
 create index ip on posts (ip);
 create index posts_op_time on posts (op, time);
+create index posts_time on posts (time);
+create index posts_body_fts on posts using gin (to_tsvector('simple', body));
+create index posts_deleted on posts (board, deleted_at) where deleted;
+
+create table post_edits (
+  id bigserial primary key,
+  post_id bigint not null references posts on delete cascade,
+  body text not null,
+  time bigint not null
+);
+create index post_edits_post_id on post_edits (post_id);
+
+create table post_links (
+  source bigint not null references posts on delete cascade,
+  target bigint not null references posts on delete cascade,
+  primary key (source, target)
+);
+create index post_links_target on post_links (target);
+
+create table reports (
+  id bigserial primary key,
+  post_id bigint not null references posts on delete cascade,
+  board text not null references boards on delete cascade,
+  reason varchar(100) not null,
+  -- Null for reports filed by the server
+  ip inet,
+  created bigint not null,
+  resolved boolean not null default false,
+  resolved_by varchar(20),
+  unique (post_id, ip)
+);
+create index reports_board on reports (board);
+create index reports_ip on reports (ip);
+
+create table notifications (
+  id bigserial primary key,
+  account varchar(20) not null references accounts on delete cascade,
+  post_id bigint not null references posts on delete cascade,
+  target bigint not null references posts on delete cascade,
+  read boolean not null default false,
+  unique (post_id, target)
+);
+create index notifications_account on notifications (account, id);
+
+create table watched_threads (
+  account varchar(20) not null references accounts on delete cascade,
+  thread bigint not null references threads on delete cascade,
+  -- Post counter of the thread, when last viewed
+  seen bigint not null,
+  primary key (account, thread)
+);
+create index watched_threads_thread on watched_threads (thread);
+
+create table ban_appeals (
+  id bigserial primary key,
+  board text not null references boards on delete cascade,
+  ip inet not null,
+  -- Post the appealed ban was issued for
+  ban_id bigint not null,
+  text varchar(1000) not null,
+  created bigint not null,
+  resolved boolean not null default false,
+  accepted boolean not null default false,
+  resolved_by varchar(20),
+  unique (board, ip, ban_id)
+);
+create index ban_appeals_board on ban_appeals (board);
+
+create table polls (
+  thread bigint primary key references threads on delete cascade,
+  question varchar(200) not null,
+  options text[] not null,
+  multiple boolean not null default false,
+  expires bigint
+);
+
+create table poll_votes (
+  thread bigint not null references polls on delete cascade,
+  voter text not null,
+  options smallint[] not null,
+  primary key (thread, voter)
+);
 
 create table news (
   id bigserial primary key,
@@ -0,0 +1,4 @@
+select count(*)
+  from notifications n
+  join posts p on p.id = n.post_id
+  where n.account = $1 and not n.read and p.deleted is not true
@@ -0,0 +1,6 @@
+select n.id, n.post_id, p.op, p.board, n.target, p.time, n.read, n.account
+  from notifications n
+  join posts p on p.id = n.post_id
+  where n.account = $1 and p.deleted is not true
+  order by n.id desc
+  limit $2
@@ -0,0 +1,4 @@
+select n.id, n.post_id, p.op, p.board, n.target, p.time, n.read, n.account
+  from notifications n
+  join posts p on p.id = n.post_id
+  where n.post_id = $1 and p.deleted is not true
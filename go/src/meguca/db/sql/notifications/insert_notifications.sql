@@ -0,0 +1,6 @@
+insert into notifications (account, post_id, target)
+  select a.id, $1, p.id
+    from posts p
+    join accounts a on a.id = p.name
+    where p.id = any($2) and a.id != $3
+  on conflict do nothing
@@ -0,0 +1,3 @@
+update notifications
+  set read = true
+  where account = $1 and id = any($2)
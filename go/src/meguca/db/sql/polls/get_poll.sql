@@ -0,0 +1,3 @@
+SELECT question, options, multiple, expires
+FROM polls
+WHERE thread = $1
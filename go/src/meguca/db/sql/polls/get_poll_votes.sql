@@ -0,0 +1,4 @@
+SELECT o, count(*)
+FROM poll_votes, unnest(options) AS o
+WHERE thread = $1
+GROUP BY o
@@ -0,0 +1,2 @@
+INSERT INTO polls (thread, question, options, multiple, expires)
+  VALUES ($1, $2, $3, $4, $5)
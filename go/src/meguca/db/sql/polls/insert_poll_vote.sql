@@ -0,0 +1,3 @@
+INSERT INTO poll_votes (thread, voter, options)
+  VALUES ($1, $2, $3)
+  RETURNING bump_thread($1, false, false, false, 0)
@@ -0,0 +1,2 @@
+select count(*) from posts
+  where time > $3 and op != $2 and body = $1
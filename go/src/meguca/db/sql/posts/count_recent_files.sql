@@ -0,0 +1,3 @@
+select count(*) from post_files pf
+  join posts p on p.id = pf.post_id
+  where pf.file_hash = any($1) and p.time > $2
@@ -0,0 +1,8 @@
+WITH l AS (
+  DELETE FROM post_links WHERE source = $1
+    RETURNING target
+)
+SELECT bump_thread(op, false, false, false, 0)
+  FROM posts
+  WHERE id IN (SELECT target FROM l)
+  GROUP BY op
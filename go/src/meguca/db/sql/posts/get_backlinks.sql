@@ -0,0 +1,5 @@
+SELECT l.target, p.id, p.op, p.board
+  FROM post_links l
+  JOIN posts p ON p.id = l.source
+  WHERE l.target = ANY($1::bigint[]) AND p.deleted IS NOT TRUE
+  ORDER BY p.id
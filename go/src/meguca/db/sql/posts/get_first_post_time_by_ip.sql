@@ -0,0 +1 @@
+SELECT time FROM posts WHERE ip = $1 ORDER BY time LIMIT 1
@@ -1,4 +1,4 @@
-SELECT p.id, p.time, p.auth, a.id, a.name, p.body, p.links, p.commands, a.settings, p.op, p.board
+SELECT p.id, p.time, p.auth, a.id, a.name, p.body, p.links, p.commands, a.settings, p.sage, p.trip, p.op, p.board
 FROM posts p
 LEFT JOIN accounts a ON a.id = p.name
-WHERE p.id = $1
+WHERE p.id = $1 AND p.deleted IS NOT TRUE
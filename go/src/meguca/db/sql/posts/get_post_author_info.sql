@@ -0,0 +1,2 @@
+select op, board, time, name, token, host(ip), password from posts
+  where id = $1 and deleted is not true
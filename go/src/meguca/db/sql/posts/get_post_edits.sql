@@ -0,0 +1,3 @@
+select body, time from post_edits
+  where post_id = $1
+  order by id
@@ -2,5 +2,5 @@ SELECT i.*
 FROM posts p
 JOIN post_files pf ON pf.post_id = p.id
 JOIN images i ON i.sha1 = pf.file_hash
-WHERE p.id = $1
+WHERE p.id = $1 AND p.deleted IS NOT TRUE
 ORDER BY pf.id
@@ -12,5 +12,5 @@ FROM post_reacts AS pr
   INNER JOIN user_reacts AS ur ON ur.post_react_id = pr.id
   INNER JOIN posts AS p ON p.id = pr.post_id
   INNER JOIN smiles on pr.smile_id = smiles.id
-WHERE p.op = $1
+WHERE p.op = $1 AND p.deleted IS NOT TRUE
 ORDER BY post_id;
@@ -0,0 +1,9 @@
+WITH l AS (
+  INSERT INTO post_links (source, target)
+    SELECT $1, id FROM posts WHERE id = ANY($2::bigint[])
+    ON CONFLICT DO NOTHING
+)
+SELECT bump_thread(op, false, false, false, 0)
+  FROM posts
+  WHERE id = ANY($2::bigint[])
+  GROUP BY op
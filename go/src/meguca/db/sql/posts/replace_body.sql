@@ -1,3 +1,4 @@
 update posts
-  set body = $2
+  set body = $2, links = $3, commands = $4
   where id = $1
+  returning bump_thread(op, false, false, false, 0)
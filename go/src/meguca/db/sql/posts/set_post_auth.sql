@@ -0,0 +1,3 @@
+update posts
+  set token = $2, password = $3, trip = $4
+  where id = $1
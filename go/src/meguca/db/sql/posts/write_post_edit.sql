@@ -0,0 +1,3 @@
+insert into post_edits (post_id, body, time)
+  select id, body, $2 from posts
+    where id = $1
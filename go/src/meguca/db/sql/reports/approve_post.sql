@@ -0,0 +1,12 @@
+WITH files AS (
+  SELECT count(*) AS cnt FROM post_files WHERE post_id = $1
+)
+
+UPDATE posts
+  SET deleted = false, deleted_at = NULL, held = false
+  FROM files
+  WHERE id = $1 AND held
+
+RETURNING
+  log_moderation(16::smallint, board, id, $2),
+  bump_thread(op, true, false, false, files.cnt)
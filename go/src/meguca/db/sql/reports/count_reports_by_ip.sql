@@ -0,0 +1,2 @@
+select count(*) from reports
+  where ip = $1 and created > $2
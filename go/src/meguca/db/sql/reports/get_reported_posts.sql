@@ -0,0 +1,2 @@
+select distinct post_id from reports
+  where board = $1 and id = any($2::bigint[]) and not resolved
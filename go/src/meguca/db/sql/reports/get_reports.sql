@@ -0,0 +1,7 @@
+select r.id, r.post_id, p.op, r.board, r.reason, r.created
+  from reports r
+  join posts p on p.id = r.post_id
+  where r.board = $1 and not r.resolved
+    -- Keep held posts, which are deleted until approved
+    and (p.deleted is not true or p.held)
+  order by r.created
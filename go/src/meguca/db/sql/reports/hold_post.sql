@@ -0,0 +1,15 @@
+-- Hide a post until a moderator approves it. Held posts are deleted without
+-- a moderator and flagged as held.
+WITH files AS (
+  SELECT count(*) AS cnt FROM post_files WHERE post_id = $1
+)
+
+UPDATE posts
+  SET deleted = true,
+    deleted_at = floor(extract(epoch from now())),
+    deleted_by = NULL,
+    held = true
+  FROM files
+  WHERE id = $1 AND deleted IS NOT TRUE
+
+RETURNING bump_thread(op, false, true, false, files.cnt)
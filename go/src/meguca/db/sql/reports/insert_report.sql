@@ -0,0 +1,2 @@
+insert into reports (post_id, board, reason, ip, created)
+  values ($1, $2, $3, $4, $5)
@@ -0,0 +1,3 @@
+update reports
+  set resolved = true, resolved_by = $3
+  where board = $1 and not resolved and post_id = any($2::bigint[])
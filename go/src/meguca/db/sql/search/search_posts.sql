@@ -0,0 +1,16 @@
+SELECT p.id, p.time, p.auth, a.id, a.name, p.body, p.links, p.commands, a.settings, p.sage, p.trip, p.op, p.board, t.subject
+FROM posts p
+JOIN threads t ON t.id = p.op
+LEFT JOIN accounts a ON a.id = p.name
+WHERE p.board = ANY($2)
+  AND p.deleted IS NOT TRUE
+  AND (
+    to_tsvector('simple', p.body) @@ plainto_tsquery('simple', $1)
+    OR (p.id = p.op AND to_tsvector('simple', t.subject) @@ plainto_tsquery('simple', $1))
+  )
+  AND ($3::bigint IS NULL OR p.time >= $3)
+  AND ($4::bigint IS NULL OR p.time < $4)
+  AND (NOT $5::boolean OR EXISTS (SELECT 1 FROM post_files pf WHERE pf.post_id = p.id))
+  AND ($6::text IS NULL OR p.name = $6)
+ORDER BY p.id DESC
+LIMIT $7 OFFSET $8
@@ -2,5 +2,5 @@ SELECT p.id, i.*
 FROM posts p
 JOIN post_files pf ON pf.post_id = p.id
 JOIN images i ON i.sha1 = pf.file_hash
-WHERE p.op = $1
+WHERE p.op = $1 AND p.deleted IS NOT TRUE
 ORDER BY pf.id
@@ -1,8 +1,8 @@
 WITH t AS (
-  SELECT p.id AS post_id, p.time, p.auth, a.id, a.name, p.body, p.links, p.commands, a.settings
+  SELECT p.id AS post_id, p.time, p.auth, a.id, a.name, p.body, p.links, p.commands, a.settings, p.sage, p.trip
   FROM posts p
   LEFT JOIN accounts a ON a.id = p.name
-  WHERE op = $1 AND p.id != $1
+  WHERE op = $1 AND p.id != $1 AND p.deleted IS NOT TRUE
   ORDER BY p.id DESC
   LIMIT $2
 )
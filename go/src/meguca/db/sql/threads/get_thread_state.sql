@@ -0,0 +1,2 @@
+select archived, locked from threads
+  where id = $1
@@ -0,0 +1,10 @@
+update threads
+  set archived = true,
+    replyTime = floor(extract(epoch from now()))
+  where id in (
+    select id from threads
+      where board = $1 and not archived
+      order by sticky desc, bumpTime desc
+      offset $2
+  )
+  returning id
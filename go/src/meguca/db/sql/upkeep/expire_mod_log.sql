@@ -1,2 +1,2 @@
 delete from mod_log
-  where created < now() + '-7 days'
+  where created < (now() at time zone 'utc') - $1::int * interval '1 day'
@@ -1,2 +1,2 @@
-UPDATE posts SET ip = NULL
+UPDATE posts SET ip = NULL, token = NULL
 WHERE time < EXTRACT(EPOCH FROM now() - INTERVAL '30 days') and ip IS NOT NULL
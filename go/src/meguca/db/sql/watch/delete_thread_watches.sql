@@ -0,0 +1,2 @@
+delete from watched_threads
+  where thread = any($1)
@@ -0,0 +1,4 @@
+select w.account, greatest(t.postCtr - w.seen, 0)
+  from watched_threads w
+  join threads t on t.id = w.thread
+  where w.thread = $1
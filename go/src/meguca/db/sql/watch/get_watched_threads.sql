@@ -0,0 +1,14 @@
+select t.id, t.board, t.subject, t.postCtr, greatest(t.postCtr - w.seen, 0)
+  from watched_threads w
+  join threads t on t.id = w.thread
+  join boards b on b.id = t.board
+  where w.account = $1
+    and (not b.modOnly
+      or w.account = 'admin'
+      or exists (
+        select 1
+          from staff s
+          where s.board = t.board
+            and s.account = w.account
+            and s.position = any($2)))
+  order by t.bumpTime desc
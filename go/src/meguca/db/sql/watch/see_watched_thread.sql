@@ -0,0 +1,4 @@
+update watched_threads w
+  set seen = t.postCtr
+  from threads t
+  where w.account = $1 and w.thread = $2 and t.id = w.thread
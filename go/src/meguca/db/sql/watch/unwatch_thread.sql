@@ -0,0 +1,2 @@
+delete from watched_threads
+  where account = $1 and thread = $2
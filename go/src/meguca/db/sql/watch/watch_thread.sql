@@ -0,0 +1,6 @@
+insert into watched_threads (account, thread, seen)
+  select $1, id, postCtr from threads
+    where id = $2 and not archived
+  on conflict (account, thread) do update
+    set seen = excluded.seen
+  returning thread
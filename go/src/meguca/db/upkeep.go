@@ -4,8 +4,12 @@ package db
 
 import (
 	"meguca/assets"
+	"meguca/common"
+	"meguca/config"
 	"strings"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 // Run database clean up tasks at server start and regular intervals.
@@ -32,12 +36,23 @@ func runCleanupTasks() {
 func runFiveMinuteTasks() {
 	runPrepared("expire_post_tokens", "expire_image_tokens", "expire_bans")
 	logError("file cleanup", deleteUnusedFiles())
-
+	logError("thread archiving", archiveThreads())
 }
 
 func runHourTasks() {
 	runPrepared("expire_user_sessions", "remove_identity_info", "remove_unique_id")
 	logError("reactions cleanup", deleteUnusedReactions())
+	logError("mod log expiry", expireModLog())
+}
+
+// Delete moderation log entries older than the configured retention period.
+// The log is kept forever by default.
+func expireModLog() error {
+	days := config.Get().ModLogRetention
+	if days <= 0 {
+		return nil
+	}
+	return execPrepared("expire_mod_log", days)
 }
 
 // Delete reactions with smiles that not exist
@@ -80,6 +95,60 @@ func runPrepared(ids ...string) {
 	}
 }
 
+// Move threads past the board's live thread limit into the archive.
+func archiveThreads() (err error) {
+	for _, board := range config.GetAllBoardIDs() {
+		if board == "all" {
+			continue
+		}
+		max := config.GetBoardConfig(board).MaxThreads
+		if max <= 0 {
+			max = common.DefaultMaxThreads
+		}
+		err = archiveBoardThreads(board, max)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func archiveBoardThreads(board string, max int) (err error) {
+	r, err := prepared["archive_threads"].Query(board, max)
+	if err != nil {
+		return
+	}
+	defer r.Close()
+
+	ids := make([]uint64, 0, 16)
+	for r.Next() {
+		var id uint64
+		err = r.Scan(&id)
+		if err != nil {
+			return
+		}
+		ids = append(ids, id)
+	}
+	err = r.Err()
+	if err != nil || len(ids) == 0 {
+		return
+	}
+
+	// Archived threads can no longer receive posts
+	err = execPrepared("delete_thread_watches", pq.Array(ids))
+	if err != nil || IsTest {
+		return
+	}
+
+	for _, id := range ids {
+		err = common.ArchiveThread(id)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
 // Delete files not used in any posts.
 func deleteUnusedFiles() (err error) {
 	r, err := prepared["delete_unused_files"].Query()
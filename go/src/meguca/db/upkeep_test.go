@@ -22,7 +22,7 @@ func TestOpenPostClosing(t *testing.T) {
 	writeSampleBoard(t)
 	writeSampleThread(t)
 	common.ParseBody = func(_ []byte, _ string) (
-		[][2]uint64, []common.Command, error,
+		common.Links, []common.Command, error,
 	) {
 		return nil, nil, nil
 	}
@@ -276,3 +276,43 @@ func TestDeleteOldThreads(t *testing.T) {
 		assertThreadDeleted(t, 2, false)
 	})
 }
+
+func TestExpireModLog(t *testing.T) {
+	defer config.Set(config.DefaultServerConfig)
+
+	cases := [...]struct {
+		name      string
+		retention int
+		left      int
+	}{
+		{"kept by default", 0, 2},
+		{"expired", 7, 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assertTableClear(t, "mod_log")
+			for _, d := range [...]time.Duration{0, eightDays} {
+				assertExec(t,
+					`insert into mod_log (type, board, id, by, created)
+						values (0, 'a', 1, 'admin', $1)`,
+					time.Now().UTC().Add(-d))
+			}
+			conf := config.DefaultServerConfig
+			conf.ModLogRetention = c.retention
+			config.Set(conf)
+
+			if err := expireModLog(); err != nil {
+				t.Fatal(err)
+			}
+			var left int
+			err := db.QueryRow(`select count(*) from mod_log`).Scan(&left)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if left != c.left {
+				t.Fatalf("unexpected entry count: %d : %d", c.left, left)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+// Threads watched by accounts
+
+package db
+
+import (
+	"meguca/auth"
+
+	"github.com/lib/pq"
+)
+
+// Staff positions, that can view threads on mod-only boards
+var modOnlyPositions = pq.StringArray{
+	auth.Moderator.String(),
+	auth.BoardOwner.String(),
+}
+
+// WatchThread starts watching a thread or marks an already watched one as
+// viewed. Returns sql.ErrNoRows, if the thread does not exist or is
+// archived.
+func WatchThread(userID string, id uint64) error {
+	return prepared["watch_thread"].QueryRow(userID, id).Scan(&id)
+}
+
+// UnwatchThread stops watching a thread
+func UnwatchThread(userID string, id uint64) error {
+	return execPrepared("unwatch_thread", userID, id)
+}
+
+// SeeWatchedThread resets the unread post counter of a thread, if it is
+// watched by the account
+func SeeWatchedThread(userID string, id uint64) error {
+	return execPrepared("see_watched_thread", userID, id)
+}
+
+// GetWatchedThreads retrieves all threads watched by an account
+func GetWatchedThreads(userID string) (ts auth.WatchedThreads, err error) {
+	ts = make(auth.WatchedThreads, 0, 16)
+	r, err := prepared["get_watched_threads"].Query(userID, modOnlyPositions)
+	if err != nil {
+		return
+	}
+	defer r.Close()
+	for r.Next() {
+		var t auth.WatchedThread
+		err = r.Scan(&t.ID, &t.Board, &t.Subject, &t.PostCtr, &t.Unread)
+		if err != nil {
+			return
+		}
+		ts = append(ts, t)
+	}
+	err = r.Err()
+	return
+}
+
+// GetThreadWatchers retrieves the accounts watching a thread with their
+// unread post counts
+func GetThreadWatchers(id uint64) (unread map[string]uint32, err error) {
+	unread = make(map[string]uint32)
+	r, err := prepared["get_thread_watchers"].Query(id)
+	if err != nil {
+		return
+	}
+	defer r.Close()
+	for r.Next() {
+		var (
+			userID string
+			n      uint32
+		)
+		err = r.Scan(&userID, &n)
+		if err != nil {
+			return
+		}
+		unread[userID] = n
+	}
+	err = r.Err()
+	return
+}
@@ -0,0 +1,172 @@
+package db
+
+import (
+	"database/sql"
+	"meguca/auth"
+	"meguca/config"
+	. "meguca/test"
+	"testing"
+	"time"
+)
+
+// Write public board a with threads 1 and 2, mod-only board m with thread 3
+// and the passed accounts
+func writeWatchSample(t *testing.T, accounts ...string) {
+	assertTableClear(t, "boards", "accounts")
+	for _, id := range accounts {
+		if err := RegisterAccount(id, []byte{1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, c := range [...]config.BoardConfig{
+		{BoardPublic: config.BoardPublic{ID: "a"}},
+		{BoardPublic: config.BoardPublic{ID: "m"}, ModOnly: true},
+	} {
+		if err := WriteBoard(nil, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now()
+	writeExpiringThreads(t, threadExpiryCases{
+		{1, "a", now},
+		{2, "a", now.Add(-time.Hour)},
+		{3, "m", now},
+	})
+}
+
+func assertWatched(t *testing.T, userID string, std auth.WatchedThreads) {
+	t.Helper()
+	ts, err := GetWatchedThreads(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertDeepEquals(t, ts, std)
+}
+
+func TestWatchThread(t *testing.T) {
+	writeWatchSample(t, "alice")
+	assertExec(t, `update threads set archived = true where id = 2`)
+
+	cases := [...]struct {
+		name string
+		id   uint64
+		err  error
+	}{
+		{"live thread", 1, nil},
+		{"archived thread", 2, sql.ErrNoRows},
+		{"missing thread", 99, sql.ErrNoRows},
+	}
+
+	for i := range cases {
+		c := cases[i]
+		t.Run(c.name, func(t *testing.T) {
+			if err := WatchThread("alice", c.id); err != c.err {
+				UnexpectedError(t, err)
+			}
+		})
+	}
+
+	t.Run("unread posts", func(t *testing.T) {
+		assertExec(t, `update threads set postCtr = 5 where id = 1`)
+		assertWatched(t, "alice", auth.WatchedThreads{
+			{ID: 1, Board: "a", PostCtr: 5, Unread: 5},
+		})
+	})
+
+	t.Run("seen", func(t *testing.T) {
+		if err := SeeWatchedThread("alice", 1); err != nil {
+			t.Fatal(err)
+		}
+		assertWatched(t, "alice", auth.WatchedThreads{
+			{ID: 1, Board: "a", PostCtr: 5},
+		})
+	})
+
+	t.Run("watchers", func(t *testing.T) {
+		assertExec(t, `update threads set postCtr = 7 where id = 1`)
+		unread, err := GetThreadWatchers(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		AssertDeepEquals(t, unread, map[string]uint32{"alice": 2})
+	})
+
+	t.Run("watch again", func(t *testing.T) {
+		if err := WatchThread("alice", 1); err != nil {
+			t.Fatal(err)
+		}
+		assertWatched(t, "alice", auth.WatchedThreads{
+			{ID: 1, Board: "a", PostCtr: 7},
+		})
+	})
+
+	t.Run("unwatch", func(t *testing.T) {
+		if err := UnwatchThread("alice", 1); err != nil {
+			t.Fatal(err)
+		}
+		assertWatched(t, "alice", auth.WatchedThreads{})
+	})
+}
+
+func TestArchiveBoardThreadsWatches(t *testing.T) {
+	writeWatchSample(t, "alice")
+	for _, id := range [...]uint64{1, 2} {
+		if err := WatchThread("alice", id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Thread 2 was bumped least recently and is archived
+	if err := archiveBoardThreads("a", 1); err != nil {
+		t.Fatal(err)
+	}
+	assertWatched(t, "alice", auth.WatchedThreads{
+		{ID: 1, Board: "a"},
+	})
+}
+
+func TestGetWatchedThreadsModOnly(t *testing.T) {
+	writeWatchSample(t,
+		"admin", "owner", "moderator", "janitor", "othermod", "user")
+	staff := map[string]auth.Staff{
+		"m": {
+			{UserID: "owner", Position: auth.BoardOwner},
+			{UserID: "moderator", Position: auth.Moderator},
+			{UserID: "janitor", Position: auth.Janitor},
+		},
+		"a": {
+			{UserID: "othermod", Position: auth.Moderator},
+		},
+	}
+	for board, s := range staff {
+		if err := WriteStaff(nil, board, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := [...]struct {
+		userID  string
+		visible bool
+	}{
+		{"admin", true},
+		{"owner", true},
+		{"moderator", true},
+		{"janitor", false},
+		{"othermod", false},
+		{"user", false},
+	}
+
+	for i := range cases {
+		c := cases[i]
+		t.Run(c.userID, func(t *testing.T) {
+			if err := WatchThread(c.userID, 3); err != nil {
+				t.Fatal(err)
+			}
+			std := auth.WatchedThreads{}
+			if c.visible {
+				std = append(std, auth.WatchedThread{ID: 3, Board: "m"})
+			}
+			assertWatched(t, c.userID, std)
+		})
+	}
+}
@@ -87,6 +87,34 @@ func GetByIPAndBoard(ip, board string) []common.Client {
 	return cls
 }
 
+// GetByThread retrieves all Clients synced to a thread
+func GetByThread(op uint64) []common.Client {
+	clients.RLock()
+	defer clients.RUnlock()
+
+	cls := make([]common.Client, 0, 16)
+	for cl, sync := range clients.clients {
+		if sync.op == op {
+			cls = append(cls, cl)
+		}
+	}
+	return cls
+}
+
+// GetByUserID retrieves all Clients logged in as an account
+func GetByUserID(userID string) []common.Client {
+	clients.RLock()
+	defer clients.RUnlock()
+
+	cls := make([]common.Client, 0, 4)
+	for cl := range clients.clients {
+		if cl.UserID() == userID {
+			cls = append(cls, cl)
+		}
+	}
+	return cls
+}
+
 // All returns all currently connected clients
 func All() []common.Client {
 	clients.RLock()
@@ -18,6 +18,10 @@ const (
 	deleteImage
 	reactToPost
 	smilesUpdated
+	archiveThread
+	lockThread
+	unlockThread
+	undeletePost
 )
 
 type postMessage struct {
@@ -72,6 +76,8 @@ type Feed struct {
 	open map[uint64]openPostCacheEntry
 	// Deleted and banned posts
 	deleted, deletedImage, banned []uint64
+	// Thread was moved to the archive or locked by a moderator
+	archived, locked bool
 }
 
 // Read existing posts into cache and start main loop
@@ -81,6 +87,12 @@ func (f *Feed) Start() (err error) {
 	if err != nil {
 		return
 	}
+	state, err := db.GetThreadState(nil, f.id)
+	if err != nil {
+		return
+	}
+	f.archived = state.Archived
+	f.locked = state.Locked
 	f.recent = make(map[uint64]int64, len(recent)*2)
 	f.open = make(map[uint64]openPostCacheEntry, 16)
 	for _, p := range recent {
@@ -174,9 +186,10 @@ func (f *Feed) Start() (err error) {
 			// Set the body of an open post and propagate
 			case msg := <-f.setOpenBody:
 				f.startIfPaused()
-				p := f.open[msg.id]
-				p.body = msg.body
-				f.open[msg.id] = p
+				if p, ok := f.open[msg.id]; ok {
+					p.body = msg.body
+					f.open[msg.id] = p
+				}
 				f.write(msg.msg)
 
 			// Various post-related messages
@@ -197,8 +210,22 @@ func (f *Feed) Start() (err error) {
 					f.banned = append(f.banned, msg.id)
 				case deletePost:
 					f.deleted = append(f.deleted, msg.id)
+				case undeletePost:
+					for i, id := range f.deleted {
+						if id == msg.id {
+							f.deleted = append(f.deleted[:i], f.deleted[i+1:]...)
+							break
+						}
+					}
 				case deleteImage:
 					f.deletedImage = append(f.deletedImage, msg.id)
+				case archiveThread:
+					f.archived = true
+					f.open = make(map[uint64]openPostCacheEntry)
+				case lockThread:
+					f.locked = true
+				case unlockThread:
+					f.locked = false
 				}
 				f.write(msg.msg)
 			}
@@ -274,6 +301,12 @@ func (f *Feed) genSyncMessage() []byte {
 	encodeUints("banned", f.banned)
 	encodeUints("deleted", f.deleted)
 	encodeUints("deletedImage", f.deletedImage)
+	if f.archived {
+		b = append(b, `,"archived":true`...)
+	}
+	if f.locked {
+		b = append(b, `,"locked":true`...)
+	}
 
 	// TODO: We send all thread reactions to connected client,
 	// although later he gonna call api to get all self reactions.
@@ -347,10 +380,26 @@ func (f *Feed) deletePost(id uint64, msg []byte) {
 	f._sendPostMessage(deletePost, id, msg)
 }
 
+func (f *Feed) undeletePost(id uint64, msg []byte) {
+	f._sendPostMessage(undeletePost, id, msg)
+}
+
 func (f *Feed) deleteImage(id uint64, msg []byte) {
 	f._sendPostMessage(deleteImage, id, msg)
 }
 
+func (f *Feed) archiveThread(msg []byte) {
+	f._sendPostMessage(archiveThread, f.id, msg)
+}
+
+func (f *Feed) lockThread(locked bool, msg []byte) {
+	typ := unlockThread
+	if locked {
+		typ = lockThread
+	}
+	f._sendPostMessage(typ, f.id, msg)
+}
+
 // Set body of an open post and send update message to clients
 func (f *Feed) SetOpenBody(id uint64, body, msg []byte) {
 	f.setOpenBody <- postBodyModMessage{
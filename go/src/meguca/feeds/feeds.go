@@ -5,6 +5,7 @@ package feeds
 
 import (
 	"meguca/common"
+	"meguca/config"
 	"meguca/db"
 	"sync"
 )
@@ -22,8 +23,12 @@ func init() {
 	common.ClosePost = ClosePost
 	common.BanPost = BanPost
 	common.DeletePost = DeletePost
+	common.UndeletePost = UndeletePost
 	common.DeleteImage = DeleteImage
 	common.SpoilerImage = SpoilerImage
+	common.ArchiveThread = ArchiveThread
+	common.LockThread = LockThread
+	common.RedirectThread = RedirectThread
 }
 
 // Container for managing client<->update-feed assignment and interaction
@@ -158,6 +163,22 @@ func DeletePost(id, op uint64) error {
 	})
 }
 
+// Propagate a message about a deleted post being restored. The message
+// carries the whole post, so clients can render it again.
+func UndeletePost(id, op uint64) error {
+	post, err := db.GetPost(id)
+	if err != nil {
+		return err
+	}
+	msg, err := common.EncodeMessage(common.MessageUndeletePost, post)
+	if err != nil {
+		return err
+	}
+	return sendIfExists(op, func(f *Feed) {
+		f.undeletePost(id, msg)
+	})
+}
+
 // Propagate a message about an image being deleted from a post
 func DeleteImage(id, op uint64) error {
 	msg, err := common.EncodeMessage(common.MessageDeleteImage, id)
@@ -180,6 +201,137 @@ func SpoilerImage(id, op uint64) error {
 	})
 }
 
+// Propagate a message about a post's body being edited
+func EditPost(
+	id, op uint64,
+	body string,
+	links common.Links,
+	commands common.Commands,
+) error {
+	msg, err := common.EncodeMessage(common.MessageEditPost, struct {
+		ID       uint64          `json:"id"`
+		Body     string          `json:"body"`
+		Links    common.Links    `json:"links,omitempty"`
+		Commands common.Commands `json:"commands,omitempty"`
+	}{id, body, links, commands})
+	if err != nil {
+		return err
+	}
+	return sendIfExists(op, func(f *Feed) {
+		f.SetOpenBody(id, []byte(body), msg)
+	})
+}
+
+// Propagate messages about a post linking posts in other threads, so
+// their backlinks are updated. Links inside the same thread are handled
+// by clients on post insertion. Posts on mod-only boards don't propagate
+// backlinks to other boards.
+func InsertBacklinks(post common.StandalonePost) error {
+	bl := common.Link{ID: post.ID, OP: post.OP, Board: post.Board}
+	modOnly := config.IsModOnlyBoard(post.Board)
+	for _, l := range post.Links {
+		if l.OP == post.OP || modOnly && l.Board != post.Board {
+			continue
+		}
+		msg, err := common.EncodeMessage(common.MessageBacklink, struct {
+			ID   uint64      `json:"id"`
+			Link common.Link `json:"link"`
+		}{l.ID, bl})
+		if err != nil {
+			return err
+		}
+		SendTo(l.OP, msg)
+	}
+	return nil
+}
+
+// Deliver reply notifications created by a post to all clients of the
+// notified accounts, regardless of the thread they are synced to
+func SendNotifications(id uint64) error {
+	ns, err := db.GetPostNotifications(id)
+	if err != nil {
+		return err
+	}
+	for _, n := range ns {
+		msg, err := common.EncodeMessage(common.MessageReplyNotification, n)
+		if err != nil {
+			return err
+		}
+		for _, cl := range GetByUserID(n.UserID) {
+			cl.Send(msg)
+		}
+	}
+	return nil
+}
+
+// Send the unread post counts of a thread to all clients of the accounts
+// watching it
+func UpdateWatchers(op uint64) error {
+	unread, err := db.GetThreadWatchers(op)
+	if err != nil {
+		return err
+	}
+	for userID, n := range unread {
+		msg, err := common.EncodeMessage(common.MessageWatchedThread, struct {
+			ID     uint64 `json:"id"`
+			Unread uint32 `json:"unread"`
+		}{op, n})
+		if err != nil {
+			return err
+		}
+		for _, cl := range GetByUserID(userID) {
+			cl.Send(msg)
+		}
+	}
+	return nil
+}
+
+// Propagate updated vote tallies of a thread poll
+func UpdatePoll(op uint64, votes []uint64) error {
+	msg, err := common.EncodeMessage(common.MessagePollUpdated, struct {
+		ID    uint64   `json:"id"`
+		Votes []uint64 `json:"votes"`
+	}{op, votes})
+	if err != nil {
+		return err
+	}
+	SendTo(op, msg)
+	return nil
+}
+
+// Propagate a message about a thread being archived
+func ArchiveThread(op uint64) error {
+	msg, err := common.EncodeMessage(common.MessageThreadArchived, op)
+	if err != nil {
+		return err
+	}
+	return sendIfExists(op, func(f *Feed) {
+		f.archiveThread(msg)
+	})
+}
+
+// Propagate a message about a thread being locked or unlocked
+func LockThread(op uint64, locked bool) error {
+	msg, err := common.EncodeMessage(common.MessageThreadLocked, struct {
+		ID     uint64 `json:"id"`
+		Locked bool   `json:"locked"`
+	}{op, locked})
+	if err != nil {
+		return err
+	}
+	return sendIfExists(op, func(f *Feed) {
+		f.lockThread(locked, msg)
+	})
+}
+
+// Redirect all clients synced to a thread to another thread.
+// Used when the thread is moved or merged into another one.
+func RedirectThread(op uint64, board string, id uint64) {
+	for _, cl := range GetByThread(op) {
+		cl.RedirectThread(board, id)
+	}
+}
+
 // Remove all existing feeds and clients. Used only in tests.
 func Clear() {
 	feeds.mu.Lock()
@@ -1,10 +1,45 @@
 package feeds
 
 import (
+	"encoding/json"
+	"meguca/auth"
+	"meguca/common"
+	"meguca/config"
+	"meguca/db"
 	. "meguca/test"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
+func init() {
+	db.ConnArgs = db.TestConnArgs
+	db.IsTest = true
+	if err := db.LoadDB(); err != nil {
+		panic(err)
+	}
+}
+
+// Client, that records all sent messages
+type mockClient struct {
+	ip, userID string
+	mu         sync.Mutex
+	sent       []string
+}
+
+func (c *mockClient) Send(msg []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sent = append(c.sent, string(msg))
+}
+
+func (c *mockClient) Redirect(string)               {}
+func (c *mockClient) RedirectThread(string, uint64) {}
+func (c *mockClient) IP() string                    { return c.ip }
+func (c *mockClient) UserID() string                { return c.userID }
+func (c *mockClient) Close(error)                   {}
+
 func TestWriteMultipleToBuffer(t *testing.T) {
 	t.Parallel()
 
@@ -17,3 +52,98 @@ func TestWriteMultipleToBuffer(t *testing.T) {
 		LogUnexpected(t, std, s)
 	}
 }
+
+func TestSendNotifications(t *testing.T) {
+	if err := db.ClearTables("boards", "accounts"); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range [...]string{"alice", "bob"} {
+		if err := db.RegisterAccount(id, []byte{1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := config.BoardConfig{
+		BoardPublic: config.BoardPublic{ID: "a"},
+	}
+	if err := db.WriteBoard(nil, c); err != nil {
+		t.Fatal(err)
+	}
+	post := func(id uint64, userID string, links common.Links) db.Post {
+		return db.Post{
+			StandalonePost: common.StandalonePost{
+				Post: common.Post{
+					ID:     id,
+					Time:   time.Now().Unix(),
+					UserID: userID,
+					Links:  links,
+				},
+				OP:    1,
+				Board: "a",
+			},
+		}
+	}
+	if err := db.InsertThread(nil, post(1, "alice", nil), "s"); err != nil {
+		t.Fatal(err)
+	}
+	links := common.Links{{ID: 1, OP: 1, Board: "a"}}
+	if err := db.InsertPost(nil, post(2, "", links)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every client of the notified account receives the notification
+	clients := [...]struct {
+		name string
+		cl   *mockClient
+		std  int
+	}{
+		{"notified account", &mockClient{ip: "::1", userID: "alice"}, 1},
+		{"second client", &mockClient{ip: "::2", userID: "alice"}, 1},
+		{"other account", &mockClient{ip: "::3", userID: "bob"}, 0},
+		{"anonymous", &mockClient{ip: "::4"}, 0},
+	}
+	for _, c := range clients {
+		if _, err := SyncClient(c.cl, 0, "a"); err != nil {
+			t.Fatal(err)
+		}
+		defer RemoveClient(c.cl)
+	}
+
+	if err := SendNotifications(2); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range clients {
+		t.Run(c.name, func(t *testing.T) {
+			c.cl.mu.Lock()
+			defer c.cl.mu.Unlock()
+
+			if len(c.cl.sent) != c.std {
+				t.Fatalf("unexpected message count: %d : %d",
+					c.std, len(c.cl.sent))
+			}
+			if c.std == 0 {
+				return
+			}
+
+			msg := c.cl.sent[0]
+			typ := common.PrependMessageType(
+				common.MessageReplyNotification, nil)
+			if !strings.HasPrefix(msg, string(typ)) {
+				t.Fatalf("unexpected message type: %s", msg)
+			}
+			var n auth.Notification
+			err := json.Unmarshal([]byte(msg[len(typ):]), &n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			n.ID = 0
+			AssertDeepEquals(t, n, auth.Notification{
+				Post:   2,
+				OP:     1,
+				Board:  "a",
+				Target: 1,
+				Time:   n.Time,
+			})
+		})
+	}
+}
@@ -5,11 +5,14 @@ import (
 	"bytes"
 	"database/sql"
 	"meguca/common"
+	"meguca/config"
 	"meguca/db"
 	"meguca/templates"
 	"meguca/util"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	b "github.com/cutechan/blackfriday"
 )
@@ -25,33 +28,85 @@ func ParseSubject(s string) (string, error) {
 	return strings.TrimSpace(s), nil
 }
 
+// Check thread poll fields. Blank options are skipped. Duration is in
+// minutes, zero means the poll never expires.
+func ParsePoll(
+	question string,
+	options []string,
+	multiple bool,
+	duration int,
+) (*common.Poll, error) {
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return nil, common.ErrInvalidPoll
+	}
+	if utf8.RuneCountInString(question) > common.MaxLenPollQuestion {
+		return nil, common.ErrPollTooLong
+	}
+	opts := make([]string, 0, len(options))
+	for _, o := range options {
+		o = strings.TrimSpace(o)
+		if o == "" {
+			continue
+		}
+		if utf8.RuneCountInString(o) > common.MaxLenPollOption {
+			return nil, common.ErrPollTooLong
+		}
+		opts = append(opts, o)
+	}
+	if len(opts) < 2 || len(opts) > common.MaxPollOptions {
+		return nil, common.ErrInvalidPoll
+	}
+	if duration < 0 || duration > common.MaxPollDuration*24*60 {
+		return nil, common.ErrInvalidPoll
+	}
+
+	p := &common.Poll{
+		Question: question,
+		Options:  opts,
+		Multiple: multiple,
+		Votes:    make([]uint64, len(opts)),
+	}
+	if duration != 0 {
+		p.Expires = time.Now().Add(time.Duration(duration) * time.Minute).Unix()
+	}
+	return p, nil
+}
+
 type parseRenderer struct {
+	board    string
 	links    common.Links
 	commands common.Commands
+	lexer    templates.TextLexer
 	*b.Html
 }
 
+func (r *parseRenderer) NormalText(out *bytes.Buffer, text []byte) {
+	r.lexer.Lex(out, text, r, r.Html.NormalText)
+}
+
 func (r *parseRenderer) PostLink(out *bytes.Buffer, text []byte) {
 	link, err := parsePostLink(text)
-	if err != nil {
+	if err != nil || link.ID == 0 {
 		return
 	}
 	r.links = append(r.links, link)
 }
 
 // Extract post links from a text fragment, verify and retrieve their
-// parenthood.
-func parsePostLink(text []byte) (link [2]uint64, err error) {
-	idStr := string(text[2:])
-	id, err := strconv.ParseUint(idStr, 10, 64)
-	if err != nil {
+// parenthood. Board-qualified links must point to a post on that board.
+func parsePostLink(text []byte) (link common.Link, err error) {
+	board, id, ok := templates.MatchPostLink(text)
+	if !ok || id == 0 { // Board links need no verification
 		return
 	}
 
-	op, err := db.GetPostOP(id)
+	postBoard, op, err := db.GetPostParenthood(id)
 	switch err {
 	case nil:
-		link = [2]uint64{id, op}
+		if board == "" || board == postBoard {
+			link = common.Link{ID: id, OP: op, Board: postBoard}
+		}
 	case sql.ErrNoRows: // Points to invalid post. Ignore.
 		err = nil
 	}
@@ -59,31 +114,47 @@ func parsePostLink(text []byte) (link [2]uint64, err error) {
 }
 
 func (r *parseRenderer) Command(out *bytes.Buffer, text []byte, c, q string) {
-	// Allow only single command per post for now.
-	if len(r.commands) > 0 {
+	if len(r.commands) >= common.MaxCommands {
+		return
+	}
+	typ, ok := templates.MatchCommand(c, q)
+	if !ok {
 		return
 	}
 
-	switch c {
-	case "roll":
+	cmd := common.Command{Type: typ}
+	switch typ {
+	case common.Roll:
 		m := templates.RollQueryRe.FindStringSubmatch(q)
-		if m != nil {
-			a, _ := strconv.Atoi(m[1])
-			b, _ := strconv.Atoi(m[2])
-			v := util.PseudoRandInt(a, b)
-			cmd := common.Command{Type: common.Roll, Roll: v}
-			r.commands = append(r.commands, cmd)
-		}
-	case "flip":
+		a, _ := strconv.Atoi(m[1])
+		b, _ := strconv.Atoi(m[2])
+		cmd.Roll = util.PseudoRandInt(a, b)
+	case common.Flip:
 		m := templates.FlipQueryRe.FindStringSubmatch(q)
-		if m != nil {
-			a, _ := strconv.Atoi(m[1])
-			b := util.PseudoRandInt(1, 100)
-			v := a >= b
-			cmd := common.Command{Type: common.Flip, Flip: v}
-			r.commands = append(r.commands, cmd)
+		a, _ := strconv.Atoi(m[1])
+		b := util.PseudoRandInt(1, 100)
+		cmd.Flip = a >= b
+	case common.Dice:
+		m := templates.DiceQueryRe.FindStringSubmatch(q)
+		n, _ := strconv.Atoi(m[1])
+		sides, _ := strconv.Atoi(m[2])
+		cmd.Dice = make([]int, n)
+		for i := range cmd.Dice {
+			cmd.Dice[i] = util.PseudoRandInt(1, sides)
+		}
+	case common.EightBall:
+		i := util.PseudoRandInt(0, len(common.EightBallAnswers)-1)
+		cmd.Text = common.EightBallAnswers[i]
+	case common.Countdown:
+		cmd.Countdown, _ = strconv.ParseInt(q, 10, 64)
+	case common.Pick:
+		items := config.GetBoardConfig(r.board).PickItems
+		if len(items) == 0 {
+			return
 		}
+		cmd.Text = items[util.PseudoRandInt(0, len(items)-1)]
 	}
+	r.commands = append(r.commands, cmd)
 }
 
 // Extract special elements from the post body which need some
@@ -92,8 +163,11 @@ func (r *parseRenderer) Command(out *bytes.Buffer, text []byte, c, q string) {
 // Run the full formatting process which is kinda superfluous (we don't
 // need resulting markup) but it shouldn't be too expensive. That would
 // guarantee that the parsing is correct (e.g. in case of code blocks).
-func ParseBody(body []byte) (common.Links, common.Commands, error) {
+func ParseBody(body []byte, board string) (
+	common.Links, common.Commands, error,
+) {
 	renderer := &parseRenderer{
+		board:    board,
 		links:    nil,
 		commands: nil,
 		Html:     b.HtmlRenderer(templates.HtmlFlags, "", "").(*b.Html),
@@ -0,0 +1,193 @@
+package parser
+
+import (
+	"meguca/common"
+	"meguca/config"
+	"meguca/db"
+	. "meguca/test"
+	"testing"
+	"time"
+)
+
+func init() {
+	db.ConnArgs = db.TestConnArgs
+	db.IsTest = true
+	if err := db.LoadDB(); err != nil {
+		panic(err)
+	}
+}
+
+func TestParseBodyCommands(t *testing.T) {
+	err := config.SetBoardConfig(config.BoardConfig{
+		BoardPublic: config.BoardPublic{ID: "a"},
+		PickItems:   []string{"foo"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := [...]struct {
+		name, body string
+		commands   common.Commands
+	}{
+		{
+			name: "no commands",
+			body: "foo bar",
+		},
+		{
+			name:     "countdown",
+			body:     "foo !countdown1700000000 bar",
+			commands: common.Commands{{Type: common.Countdown, Countdown: 1700000000}},
+		},
+		{
+			name:     "pick",
+			body:     "!pick",
+			commands: common.Commands{{Type: common.Pick, Text: "foo"}},
+		},
+		{
+			name: "invalid queries",
+			body: "!8ballx !pickx !countdown0 !roll11d6 !roll1d6+",
+		},
+		{
+			name: "inside code",
+			body: "`!8ball`",
+		},
+	}
+
+	for i := range cases {
+		c := cases[i]
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, com, err := ParseBody([]byte(c.body), "a")
+			if err != nil {
+				t.Fatal(err)
+			}
+			AssertDeepEquals(t, com, c.commands)
+		})
+	}
+
+	t.Run("random results", func(t *testing.T) {
+		t.Parallel()
+
+		_, com, err := ParseBody([]byte("!8ball, !roll3d6+2 and !roll1-6"), "a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(com) != 3 {
+			t.Fatalf("unexpected commands: %v", com)
+		}
+		if com[0].Type != common.EightBall || com[0].Text == "" {
+			t.Fatalf("invalid 8ball: %v", com[0])
+		}
+		if com[1].Type != common.Dice || len(com[1].Dice) != 3 {
+			t.Fatalf("invalid dice: %v", com[1])
+		}
+		for _, d := range com[1].Dice {
+			if d < 1 || d > 6 {
+				t.Fatalf("invalid dice: %v", com[1])
+			}
+		}
+		if com[2].Type != common.Roll || com[2].Roll < 1 || com[2].Roll > 6 {
+			t.Fatalf("invalid roll: %v", com[2])
+		}
+	})
+
+	t.Run("no pick items", func(t *testing.T) {
+		t.Parallel()
+
+		_, com, err := ParseBody([]byte("!pick"), "b")
+		if err != nil {
+			t.Fatal(err)
+		}
+		AssertDeepEquals(t, com, common.Commands(nil))
+	})
+}
+
+func TestParseBodyLinks(t *testing.T) {
+	if err := db.ClearTables("boards"); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range [...]string{"a", "b"} {
+		c := config.BoardConfig{
+			BoardPublic: config.BoardPublic{ID: id},
+		}
+		if err := db.WriteBoard(nil, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, p := range [...]struct {
+		id    uint64
+		board string
+	}{
+		{1, "a"},
+		{3, "b"},
+	} {
+		post := db.Post{
+			StandalonePost: common.StandalonePost{
+				Post: common.Post{
+					ID:   p.id,
+					Time: time.Now().Unix(),
+				},
+				OP:    p.id,
+				Board: p.board,
+			},
+		}
+		if err := db.InsertThread(nil, post, "subject"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := [...]struct {
+		name, body string
+		links      common.Links
+	}{
+		{
+			name:  "post link",
+			body:  ">>1",
+			links: common.Links{{ID: 1, OP: 1, Board: "a"}},
+		},
+		{
+			name:  "cross-board link",
+			body:  ">>>/a/1",
+			links: common.Links{{ID: 1, OP: 1, Board: "a"}},
+		},
+		{
+			name: "cross-board links inside text",
+			body: "foo >>>/b/3 bar >>>/a/1",
+			links: common.Links{
+				{ID: 3, OP: 3, Board: "b"},
+				{ID: 1, OP: 1, Board: "a"},
+			},
+		},
+		{
+			name: "post on other board",
+			body: ">>>/b/1",
+		},
+		{
+			name: "nonexistent post",
+			body: ">>>/a/2",
+		},
+		{
+			name: "board link",
+			body: ">>>/a/",
+		},
+		{
+			name: "inside code",
+			body: "`>>>/a/1`",
+		},
+	}
+
+	for i := range cases {
+		c := cases[i]
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			links, _, err := ParseBody([]byte(c.body), "a")
+			if err != nil {
+				t.Fatal(err)
+			}
+			AssertDeepEquals(t, links, c.links)
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"meguca/auth"
+	"meguca/cache"
 	"meguca/common"
 	"meguca/config"
 	"meguca/db"
@@ -23,7 +24,7 @@ import (
 var (
 	boardNameValidation = regexp.MustCompile(`^[a-z0-9]{1,10}$`)
 	reservedBoards      = [...]string{
-		"all", "stickers", "admin",
+		"all", "stickers", "admin", "search",
 		"html", "api",
 		"static", "uploads",
 	}
@@ -214,7 +215,9 @@ func deleteBoard(w http.ResponseWriter, r *http.Request) {
 // Set the server configuration to match the one sent from the admin account
 // user
 func configureServer(w http.ResponseWriter, r *http.Request) {
-	var msg config.ServerConfig
+	// Fields missing from the form, like the anti-spam thresholds, keep their
+	// current values
+	msg := *config.Get()
 	if !decodeJSON(w, r, &msg) || !isAdmin(w, r) {
 		return
 	}
@@ -228,6 +231,26 @@ func deletePost(w http.ResponseWriter, r *http.Request) {
 	moderatePosts(w, r, auth.Moderator, db.DeletePost)
 }
 
+// Restore one or multiple posts deleted by moderators
+func undeletePost(w http.ResponseWriter, r *http.Request) {
+	moderatePosts(w, r, auth.Moderator, db.UndeletePost)
+}
+
+// Serve recently deleted posts of a board with their content to its
+// moderators
+func serveDeletedPosts(w http.ResponseWriter, r *http.Request) {
+	board := getParam(r, "board")
+	if _, ok := assertCanPerform(w, r, board, auth.Moderator); !ok {
+		return
+	}
+	posts, err := db.GetDeletedPosts(board)
+	if err != nil {
+		serveErrorJSON(w, r, aerrInternal.Hide(err))
+		return
+	}
+	serveJSON(w, r, posts)
+}
+
 // Perform a moderation action an a single post. If ok == false, the caller
 // should return.
 func moderatePost(
@@ -267,24 +290,62 @@ func moderatePosts(
 	if !decodeJSON(w, r, &ids) {
 		return
 	}
+	if moderatePostIDs(w, r, ids, level, fn) {
+		serveEmptyJSON(w, r)
+	}
+}
+
+// Perform a moderation action on already decoded post IDs. If ok == false,
+// the caller should return.
+func moderatePostIDs(
+	w http.ResponseWriter,
+	r *http.Request,
+	ids []uint64,
+	level auth.ModerationLevel,
+	fn func(id uint64, userID string) error,
+) (
+	ok bool,
+) {
 	for _, id := range ids {
-		ok := moderatePost(w, r, id, auth.Moderator, func(userID string) error {
+		ok = moderatePost(w, r, id, level, func(userID string) error {
 			return fn(id, userID)
 		})
 		if !ok {
 			return
 		}
 	}
-	serveEmptyJSON(w, r)
+	return true
+}
+
+// Serve previous bodies of an edited post
+func servePostEdits(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(getParam(r, "post"), 10, 64)
+	if err != nil {
+		text400(w, err)
+		return
+	}
+	if _, _, can := canModeratePost(w, r, id, auth.Moderator); !can {
+		return
+	}
+
+	edits, err := db.GetPostEdits(id)
+	if err != nil {
+		text500(w, r, err)
+		return
+	}
+	serveJSON(w, r, edits)
 }
 
 // Ban a specific IP from a specific board
 func ban(w http.ResponseWriter, r *http.Request) {
 	var msg struct {
-		Global   bool
-		Duration uint64
-		Reason   string
-		IDs      []uint64
+		Global     bool
+		Duration   uint64
+		Reason     string
+		IDs        []uint64
+		Scope      string
+		IPv4Prefix int
+		IPv6Prefix int
 	}
 
 	// Decode and validate
@@ -305,6 +366,25 @@ func ban(w http.ResponseWriter, r *http.Request) {
 		text400(w, errNoDuration)
 		return
 	}
+	opts := auth.BanOptions{
+		IPv4Prefix: msg.IPv4Prefix,
+		IPv6Prefix: msg.IPv6Prefix,
+	}
+	switch msg.Scope {
+	case "", "ip":
+		opts.Scope = auth.BanIP
+	case "range":
+		opts.Scope = auth.BanRange
+	case "uniqueID":
+		opts.Scope = auth.BanUniqueID
+	default:
+		serveErrorJSON(w, r, aerrInvalidBanScope)
+		return
+	}
+	if opts.Validate() != nil {
+		serveErrorJSON(w, r, aerrInvalidBanScope)
+		return
+	}
 
 	// Group posts by board
 	byBoard := make(map[string][]uint64, 2)
@@ -337,23 +417,28 @@ func ban(w http.ResponseWriter, r *http.Request) {
 	// Apply bans
 	expires := time.Now().Add(time.Duration(msg.Duration) * time.Minute)
 	for board, ids := range byBoard {
-		ips, err := db.Ban(board, msg.Reason, ss.UserID, expires, ids...)
+		ips, err := db.Ban(board, msg.Reason, ss.UserID, expires, opts,
+			ids...)
 		if err != nil {
 			text500(w, r, err)
 			return
 		}
 
-		// Redirect all banned connected clients to the /all/ board
-		for ip := range ips {
-			for _, cl := range common.GetByIPAndBoard(ip, board) {
-				cl.Redirect("all")
-			}
-		}
+		redirectBanned(board, ips)
 	}
 
 	serveEmptyJSON(w, r)
 }
 
+// Redirect all banned connected clients to the ban page
+func redirectBanned(board string, ips map[string]uint64) {
+	for ip := range ips {
+		for _, cl := range common.GetByIPAndBoard(ip, board) {
+			cl.Redirect(board + "/banned")
+		}
+	}
+}
+
 // Unban a specific board -> banned post combination
 func unban(w http.ResponseWriter, r *http.Request) {
 	board := getParam(r, "board")
@@ -435,6 +520,69 @@ func getSameIPPosts(w http.ResponseWriter, r *http.Request) {
 	serveJSON(w, r, posts)
 }
 
+// Delete all posts by the author of a post on its board and optionally ban
+// them in the same request. Serves the IDs of deleted posts.
+func nukePosts(w http.ResponseWriter, r *http.Request) {
+	var msg struct {
+		ID       uint64
+		Scope    string
+		Window   uint64
+		Ban      bool
+		Duration uint64
+		Reason   string
+	}
+	if !decodeJSON(w, r, &msg) {
+		return
+	}
+	board, userID, ok := canModeratePost(w, r, msg.ID, auth.Moderator)
+	if !ok {
+		return
+	}
+
+	var opts auth.BanOptions
+	switch msg.Scope {
+	case "", "ip":
+		opts.Scope = auth.BanIP
+	case "uniqueID":
+		opts.Scope = auth.BanUniqueID
+	default:
+		serveErrorJSON(w, r, aerrInvalidBanScope)
+		return
+	}
+	var ban *db.NukeBan
+	if msg.Ban {
+		switch {
+		case msg.Reason == "", len(msg.Reason) > common.MaxBanReasonLength:
+			serveErrorJSON(w, r, aerrInvalidReason)
+			return
+		case msg.Duration == 0:
+			text400(w, errNoDuration)
+			return
+		}
+		ban = &db.NukeBan{
+			Reason:  msg.Reason,
+			Expires: time.Now().Add(time.Duration(msg.Duration) * time.Minute),
+			Options: opts,
+		}
+	}
+
+	// Window is in minutes. Zero deletes posts of any age.
+	var since int64
+	if msg.Window != 0 {
+		since = time.Now().
+			Add(-time.Duration(msg.Window) * time.Minute).
+			Unix()
+	}
+	ids, ips, err := db.NukePosts(msg.ID, board,
+		opts.Scope == auth.BanUniqueID, since, userID, ban)
+	if err != nil {
+		text500(w, r, err)
+		return
+	}
+	redirectBanned(board, ips)
+	serveJSON(w, r, ids)
+}
+
 // Set the sticky flag of a thread
 func setThreadSticky(w http.ResponseWriter, r *http.Request) {
 	var msg struct {
@@ -444,12 +592,14 @@ func setThreadSticky(w http.ResponseWriter, r *http.Request) {
 	if !decodeJSON(w, r, &msg) {
 		return
 	}
-	if _, _, ok := canModeratePost(w, r, msg.ID, auth.Moderator); !ok {
+	_, userID, ok := canModeratePost(w, r, msg.ID, auth.Moderator)
+	if !ok {
 		return
 	}
 
-	switch err := db.SetThreadSticky(msg.ID, msg.Sticky); err {
+	switch err := db.SetThreadSticky(msg.ID, msg.Sticky, userID); err {
 	case nil:
+		serveEmptyJSON(w, r)
 	case sql.ErrNoRows:
 		text400(w, err)
 	default:
@@ -457,6 +607,149 @@ func setThreadSticky(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Set the locked flag of a thread
+func setThreadLocked(w http.ResponseWriter, r *http.Request) {
+	var msg struct {
+		ID     uint64
+		Locked bool
+	}
+	if !decodeJSON(w, r, &msg) {
+		return
+	}
+	_, userID, ok := canModeratePost(w, r, msg.ID, auth.Moderator)
+	if !ok {
+		return
+	}
+
+	switch err := db.SetThreadLocked(msg.ID, msg.Locked, userID); err {
+	case nil:
+		serveEmptyJSON(w, r)
+	case sql.ErrNoRows:
+		text400(w, err)
+	default:
+		text500(w, r, err)
+	}
+}
+
+// Retrieve the board of a thread to be moderated and assert the user is
+// its owner
+func canModerateThread(w http.ResponseWriter, r *http.Request) (
+	id uint64, board, userID string, can bool,
+) {
+	id, err := strconv.ParseUint(getParam(r, "thread"), 10, 64)
+	if err != nil {
+		serveErrorJSON(w, r, aerrNotThread)
+		return
+	}
+	op, err := db.GetPostOP(id)
+	switch {
+	case err == sql.ErrNoRows:
+		serveErrorJSON(w, r, aerrNoPost)
+		return
+	case err != nil:
+		serveErrorJSON(w, r, aerrInternal.Hide(err))
+		return
+	case op != id:
+		serveErrorJSON(w, r, aerrNotThread)
+		return
+	}
+	board, userID, can = canModeratePost(w, r, id, auth.BoardOwner)
+	return
+}
+
+// Move a thread with all of its posts to another board
+func moveThread(w http.ResponseWriter, r *http.Request) {
+	var msg struct {
+		Board string
+	}
+	if !decodeJSON(w, r, &msg) {
+		return
+	}
+	id, from, userID, ok := canModerateThread(w, r)
+	if !ok {
+		return
+	}
+	if msg.Board == from || msg.Board == "all" {
+		serveErrorJSON(w, r, aerrInvalidTarget)
+		return
+	}
+	if _, ok := assertCanPerform(w, r, msg.Board, auth.BoardOwner); !ok {
+		return
+	}
+
+	err := db.MoveThread(id, from, msg.Board, userID)
+	if err != nil {
+		serveErrorJSON(w, r, aerrInternal.Hide(err))
+		return
+	}
+	cache.DeleteBoard(from)
+	cache.DeleteBoard(msg.Board)
+	serveEmptyJSON(w, r)
+}
+
+// Merge all posts of a thread into another thread
+func mergeThread(w http.ResponseWriter, r *http.Request) {
+	var msg struct {
+		Target uint64
+	}
+	if !decodeJSON(w, r, &msg) {
+		return
+	}
+	id, from, userID, ok := canModerateThread(w, r)
+	if !ok {
+		return
+	}
+	if msg.Target == id {
+		serveErrorJSON(w, r, aerrInvalidTarget)
+		return
+	}
+	to, op, err := db.GetPostParenthood(msg.Target)
+	switch {
+	case err == sql.ErrNoRows || (err == nil && op != msg.Target):
+		serveErrorJSON(w, r, aerrInvalidTarget)
+		return
+	case err != nil:
+		serveErrorJSON(w, r, aerrInternal.Hide(err))
+		return
+	}
+	if _, ok := assertCanPerform(w, r, to, auth.BoardOwner); !ok {
+		return
+	}
+	state, err := db.GetThreadState(nil, msg.Target)
+	if err != nil {
+		serveErrorJSON(w, r, aerrInternal.Hide(err))
+		return
+	}
+	if state.Archived || state.Locked {
+		serveErrorJSON(w, r, aerrThreadClosed)
+		return
+	}
+
+	posts, err := db.MergeThread(id, msg.Target, to, userID)
+	if err != nil {
+		serveErrorJSON(w, r, aerrInternal.Hide(err))
+		return
+	}
+	cache.DeleteBoard(from)
+	cache.DeleteBoard(to)
+
+	// Show the merged posts to clients of the target thread
+	for _, id := range posts {
+		p, err := db.GetPost(id)
+		if err != nil {
+			logError(r, err)
+			continue
+		}
+		msg, err := common.EncodeMessage(common.MessageInsertPost, p.Post)
+		if err != nil {
+			logError(r, err)
+			continue
+		}
+		feeds.InsertPostInto(p, msg)
+	}
+	serveEmptyJSON(w, r)
+}
+
 // TODO(Kagami): Use transaction?
 // We will check board state consistency on board update anyway though.
 func serveAdmin(
@@ -483,7 +776,7 @@ func serveAdmin(
 		return
 	}
 
-	log, err := db.GetModLog(boards)
+	log, err := db.GetModLog(boards, db.ModLogFilter{})
 	if err != nil {
 		text500(w, r, err)
 		return
@@ -509,6 +802,36 @@ func checkBoardState(board string, state db.BoardState) (err error) {
 		err = aerrTitleTooLong
 		return
 	}
+	if state.Settings.MaxThreads < 0 || state.Settings.BumpLimit < 0 {
+		err = aerrInvalidLimit
+		return
+	}
+	if state.Settings.EditWindow < 0 {
+		err = aerrInvalidWindow
+		return
+	}
+	if len(state.Settings.PickItems) > common.MaxPickItems {
+		err = aerrInvalidPickItems
+		return
+	}
+	for _, item := range state.Settings.PickItems {
+		if item == "" || len(item) > common.MaxLenPickItem {
+			err = aerrInvalidPickItems
+			return
+		}
+	}
+	if len(state.Settings.WordFilters) > common.MaxWordFilters {
+		err = aerrBadWordFilter
+		return
+	}
+	for i := range state.Settings.WordFilters {
+		// Compile a copy, so the compared states stay the same.
+		f := state.Settings.WordFilters[i]
+		if f.Compile() != nil {
+			err = aerrBadWordFilter
+			return
+		}
+	}
 	if len(state.Staff) > common.MaxLenStaffList {
 		err = aerrTooManyStaff
 		return
@@ -0,0 +1,144 @@
+// Ban page and ban appeal handlers
+
+package server
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"meguca/auth"
+	"meguca/common"
+	"meguca/db"
+	"meguca/lang"
+	"meguca/templates"
+)
+
+type resolveAppealRequest struct {
+	ID     uint64 `json:"id"`
+	Accept bool   `json:"accept"`
+}
+
+// Show the client's ban on a board and the status of its appeal
+func banHTML(w http.ResponseWriter, r *http.Request) {
+	board := getParam(r, "board")
+	if !assertBoard(w, r, board) {
+		return
+	}
+	ip, err := auth.GetIP(r)
+	if err != nil {
+		text400(w, err)
+		return
+	}
+	ban, err := db.GetBanInfo(ip, board, getUniqueID(r))
+	switch err {
+	case nil:
+	case sql.ErrNoRows:
+		http.Redirect(w, r, fmt.Sprintf("/%s/", board), 302)
+		return
+	default:
+		text500(w, r, err)
+		return
+	}
+
+	var appeal *auth.BanAppeal
+	a, err := db.GetAppeal(ban)
+	switch err {
+	case nil:
+		appeal = &a
+	case sql.ErrNoRows:
+	default:
+		text500(w, r, err)
+		return
+	}
+
+	ss, _ := getSession(r, "")
+	l := lang.FromReq(r)
+	serveHTML(w, r, templates.Banned(ss, l, ban, appeal))
+}
+
+// Appeal the client's ban on a board. Submitted from the ban page form.
+func createAppeal(w http.ResponseWriter, r *http.Request) {
+	board := getParam(r, "board")
+	if !assertBoardAPI(w, board) {
+		return
+	}
+	ip, err := auth.GetIP(r)
+	if err != nil {
+		text400(w, err)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, jsonLimit)
+	if err = r.ParseForm(); err != nil {
+		text400(w, err)
+		return
+	}
+	text := strings.TrimSpace(r.Form.Get("text"))
+	if text == "" || utf8.RuneCountInString(text) > common.MaxLenAppeal {
+		text400(w, errInvalidAppeal)
+		return
+	}
+
+	ban, err := db.GetBanInfo(ip, board, getUniqueID(r))
+	switch err {
+	case nil:
+	case sql.ErrNoRows:
+		text400(w, errNotBanned)
+		return
+	default:
+		text500(w, r, err)
+		return
+	}
+	err = db.InsertAppeal(ban, text)
+	switch {
+	case err == nil:
+	case db.IsConflictError(err):
+		text403(w, errAlreadyAppealed)
+		return
+	default:
+		text500(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/%s/banned", board), 303)
+}
+
+// Serve unresolved appeals of a board to its moderators
+func serveAppeals(w http.ResponseWriter, r *http.Request) {
+	board := getParam(r, "board")
+	if _, ok := assertCanPerform(w, r, board, auth.Moderator); !ok {
+		return
+	}
+	appeals, err := db.GetAppeals(board)
+	if err != nil {
+		serveErrorJSON(w, r, aerrInternal.Hide(err))
+		return
+	}
+	serveJSON(w, r, appeals)
+}
+
+// Accept or deny an appeal. Accepting lifts the ban.
+func resolveAppeal(w http.ResponseWriter, r *http.Request) {
+	board := getParam(r, "board")
+	ss, ok := assertCanPerform(w, r, board, auth.Moderator)
+	if !ok {
+		return
+	}
+	var req resolveAppealRequest
+	if err := readJSON(r, &req); err != nil {
+		serveErrorJSON(w, r, err)
+		return
+	}
+
+	err := db.ResolveAppeal(board, req.ID, req.Accept, ss.UserID)
+	switch err {
+	case nil:
+		serveEmptyJSON(w, r)
+	case sql.ErrNoRows:
+		serveErrorJSON(w, r, aerrNoAppeal)
+	default:
+		serveErrorJSON(w, r, aerrInternal.Hide(err))
+	}
+}
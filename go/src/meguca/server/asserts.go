@@ -28,7 +28,7 @@ func assertNotBannedAPI(w http.ResponseWriter, r *http.Request, board string) (i
 		text400(w, err)
 		return
 	}
-	if auth.IsBanned(board, ip) {
+	if auth.IsBanned(board, ip, getUniqueID(r)) {
 		text403(w, errBanned)
 		return
 	}
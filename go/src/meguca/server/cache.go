@@ -47,6 +47,20 @@ var catalogCache = cache.FrontEnd{
 	},
 }
 
+var archiveCache = cache.FrontEnd{
+	GetCounter: func(k cache.Key) (uint64, error) {
+		return db.BoardCounter(k.Board)
+	},
+
+	GetFresh: func(k cache.Key) (interface{}, error) {
+		return db.GetBoardArchive(k.Board)
+	},
+
+	RenderHTML: func(data interface{}, json []byte, k cache.Key) []byte {
+		return []byte(templates.CatalogThreads(data.(common.Board), json, false))
+	},
+}
+
 type boardPage struct {
 	pageN     int
 	pageTotal int
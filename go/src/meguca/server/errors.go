@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 
+	"meguca/common"
 	"meguca/ipc"
+	"meguca/websockets"
 )
 
 // Error returned by API. Serialized to common shape understanable by
@@ -94,6 +96,38 @@ var (
 	aerrInvalidPosition  = aerrorNew(400, "Invalid position")
 	aerrTooManyStaff     = aerrorNew(400, "Too many staff")
 	aerrTooManyBans      = aerrorNew(400, "Too many bans")
+	aerrThreadClosed     = aerrorNew(403, "Thread is closed")
+	aerrInvalidLimit     = aerrorNew(400, "Invalid thread limit")
+	aerrInvalidWindow    = aerrorNew(400, "Invalid edit window")
+	aerrInvalidPickItems = aerrorNew(400, "Invalid pick items")
+	aerrNotAuthor        = aerrorNew(403, "Not the post author")
+	aerrEditExpired      = aerrorNew(403, "Editing window expired")
+	aerrBodyTooLong      = aerrorFrom(400, common.ErrBodyTooLong)
+	aerrTooManyLines     = aerrorNew(400, "Too many lines in post body")
+	aerrNoThreadDelete   = aerrorNew(403, "Threads can't be deleted by author")
+	aerrNoPoll           = aerrorNew(404, "No poll in thread")
+	aerrPollExpired      = aerrorNew(403, "Poll expired")
+	aerrInvalidVote      = aerrorNew(400, "Invalid vote")
+	aerrAlreadyVoted     = aerrorNew(403, "Already voted")
+	aerrNoSearchQuery    = aerrorNew(400, "No search query")
+	aerrSearchTooLong    = aerrorFrom(400, common.ErrTooLong("Search query"))
+	aerrInvalidSearch    = aerrorNew(400, "Invalid search parameters")
+	aerrPageOverflow     = aerrorFrom(404, errPageOverflow)
+	aerrNotThread        = aerrorNew(400, "Not a thread")
+	aerrInvalidTarget    = aerrorNew(400, "Invalid target")
+	aerrNoPost           = aerrorNew(404, "No such post")
+	aerrInvalidCaptcha   = aerrorFrom(403, errInvalidCaptcha)
+	aerrCaptchaRequired  = aerrorFrom(429, websockets.ErrCaptchaRequired)
+	aerrInvalidReport    = aerrorNew(400, "Invalid report reason")
+	aerrAlreadyReported  = aerrorNew(403, "Already reported")
+	aerrTooManyReports   = aerrorNew(429, "Too many reports")
+	aerrInvalidAction    = aerrorNew(400, "Invalid action")
+	aerrNoAppeal         = aerrorNew(404, "No such appeal")
+	aerrInvalidBanScope  = aerrorNew(400, "Invalid ban scope")
+	aerrInvalidLogFilter = aerrorNew(400, "Invalid mod log filter")
+	aerrInvalidFormat    = aerrorNew(400, "Invalid export format")
+	aerrBadWordFilter    = aerrorNew(400, "Invalid word filter")
+	aerrFilteredPhrase   = aerrorNew(400, "Post contains a filtered phrase")
 	aerrUnsupported      = aerrorFrom(400, ipc.ErrThumbUnsupported)
 	aerrNoTracks         = aerrorFrom(400, ipc.ErrThumbTracks)
 )
@@ -120,4 +154,7 @@ var (
 	errInvalidCaptcha   = errors.New("Invalid captcha")
 	errInvalidPassword  = errors.New("Invalid password")
 	errUserIDTaken      = errors.New("Login ID already taken")
+	errNotBanned        = errors.New("You are not banned")
+	errInvalidAppeal    = errors.New("Invalid appeal")
+	errAlreadyAppealed  = errors.New("Ban already appealed")
 )
@@ -0,0 +1,289 @@
+// Thread export as a self-contained zip archive
+
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"meguca/assets"
+	"meguca/common"
+	"meguca/db"
+	"meguca/lang"
+	"meguca/templates"
+)
+
+var (
+	// Root-relative links to static assets
+	staticLinkRe = regexp.MustCompile(`/static(?:/[\w.-]+)+`)
+	// Assets referenced by stylesheets
+	cssURLRe = regexp.MustCompile(`url\(\s*['"]?([^'")]+?)['"]?\s*\)`)
+)
+
+// File to include in a thread export. src is the location on disk and path
+// the location inside the archive. data overrides the contents of src, if
+// set.
+type exportFile struct {
+	url, src, path string
+	data           []byte
+}
+
+// Stream a zip archive with the thread JSON, a static HTML page and all
+// files posted in the thread
+func exportThread(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(getParam(r, "thread"), 10, 64)
+	if err != nil {
+		serve404(w, r)
+		return
+	}
+	board, err := db.GetPostBoard(id)
+	switch err {
+	case nil:
+	case sql.ErrNoRows:
+		serve404(w, r)
+		return
+	default:
+		text500(w, r, err)
+		return
+	}
+	ss, _ := getSession(r, board)
+	if !assertNotModOnlyAPI(w, board, ss) {
+		return
+	}
+	if !assertNotRegisteredOnlyAPI(w, board, ss) {
+		return
+	}
+	valid, err := db.ValidateOP(id, board)
+	if err != nil {
+		text500(w, r, err)
+		return
+	}
+	if !valid {
+		serveErrorJSON(w, r, aerrNotThread)
+		return
+	}
+
+	t, err := db.GetThread(id, 0)
+	if err != nil {
+		text500(w, r, err)
+		return
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		text500(w, r, err)
+		return
+	}
+	l := lang.FromReq(r)
+	closed := t.Archived || t.Locked
+	postHTML := templates.ThreadPosts(l, t, data, false)
+	html := templates.Thread(id, l, board, t.Subject, false, closed, nil,
+		[]byte(postHTML))
+
+	static, err := getExportAssets(common.WebRoot, html)
+	if err != nil {
+		text500(w, r, err)
+		return
+	}
+
+	// Point file and asset links in the page to the copies inside the
+	// archive
+	files := getExportFiles(t)
+	pairs := make([]string, 0, len(files)*2)
+	for _, f := range files {
+		pairs = append(pairs, f.url, f.path)
+	}
+	html = []byte(strings.NewReplacer(pairs...).Replace(string(html)))
+	html = staticLinkRe.ReplaceAllFunc(html, func(link []byte) []byte {
+		return link[1:]
+	})
+	files = append(files, static...)
+
+	head := w.Header()
+	head.Set("Content-Type", "application/zip")
+	head.Set("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="%s-%d.zip"`, board, id))
+
+	// Headers are sent at this point, so errors can only be logged
+	zw := zip.NewWriter(w)
+	err = writeExport(zw, data, html, files)
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		log.Printf("thread export %d: %s\n", id, err)
+	}
+}
+
+// Collect unique source files and thumbnails of all posts in a thread
+func getExportFiles(t common.Thread) (files []exportFile) {
+	seen := make(map[string]bool)
+	add := func(url string) {
+		if seen[url] {
+			return
+		}
+		seen[url] = true
+		path := assets.RelativePath(url)
+		files = append(files, exportFile{
+			url:  url,
+			src:  cleanJoin(common.ImageWebRoot, filepath.FromSlash(path)),
+			path: path,
+		})
+	}
+	posts := append(common.Posts{t.Post}, t.Posts...)
+	for _, p := range posts {
+		for _, img := range p.Files {
+			add(assets.SourcePath(img.FileType, img.SHA1))
+			add(assets.ThumbPath(img.ThumbType, img.SHA1))
+		}
+	}
+	return
+}
+
+// Collect the static assets linked from the page, including the assets their
+// stylesheets reference. Links to static assets in stylesheets are rewritten
+// relative to the stylesheet. root is the directory assets are read from.
+func getExportAssets(root string, html []byte) (files []exportFile, err error) {
+	seen := make(map[string]bool)
+	add := func(p string) {
+		p = path.Clean(p)
+		if seen[p] || p == ".." || strings.HasPrefix(p, "../") {
+			return
+		}
+		seen[p] = true
+		src := cleanJoin(root, filepath.FromSlash(p))
+		// Skip missing files and links to directories
+		if fi, err := os.Stat(src); err != nil || !fi.Mode().IsRegular() {
+			return
+		}
+		files = append(files, exportFile{
+			url:  "/" + p,
+			src:  src,
+			path: p,
+		})
+	}
+
+	// Themes can be switched in the page
+	add("static/css/light.css")
+	add("static/css/dark.css")
+	for _, link := range staticLinkRe.FindAll(html, -1) {
+		add(string(link[1:]))
+	}
+
+	// Stylesheets can add further files, so iterate by index
+	for i := 0; i < len(files); i++ {
+		if !strings.HasSuffix(files[i].path, ".css") {
+			continue
+		}
+		var css []byte
+		css, err = ioutil.ReadFile(files[i].src)
+		if err != nil {
+			return
+		}
+		dir := path.Dir(files[i].path)
+		css = cssURLRe.ReplaceAllFunc(css, func(m []byte) []byte {
+			ref := string(cssURLRe.FindSubmatch(m)[1])
+			if strings.HasPrefix(ref, "data:") ||
+				strings.HasPrefix(ref, "//") ||
+				strings.HasPrefix(ref, "#") ||
+				strings.Contains(ref, "://") {
+				return m
+			}
+			target, suffix := ref, ""
+			if j := strings.IndexAny(ref, "?#"); j != -1 {
+				target, suffix = ref[:j], ref[j:]
+			}
+			if !strings.HasPrefix(target, "/") {
+				add(path.Join(dir, target))
+				return m
+			}
+
+			target = target[1:]
+			add(target)
+			rel, err := filepath.Rel(filepath.FromSlash(dir),
+				filepath.FromSlash(target))
+			if err != nil {
+				return m
+			}
+			return []byte("url(" + filepath.ToSlash(rel) + suffix + ")")
+		})
+		files[i].data = css
+	}
+	return
+}
+
+func writeExport(
+	zw *zip.Writer,
+	data, html []byte,
+	files []exportFile,
+) (err error) {
+	if err = writeExportEntry(zw, "thread.json", data); err != nil {
+		return
+	}
+	if err = writeExportEntry(zw, "index.html", html); err != nil {
+		return
+	}
+	for _, f := range files {
+		if err = copyExportFile(zw, f); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func writeExportEntry(zw *zip.Writer, name string, buf []byte) error {
+	fw, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = fw.Write(buf)
+	return err
+}
+
+// Copy a file from disk into the archive. Files missing on disk are skipped.
+func copyExportFile(zw *zip.Writer, file exportFile) (err error) {
+	var r io.Reader
+	if file.data != nil {
+		r = bytes.NewReader(file.data)
+	} else {
+		var f *os.File
+		f, err = os.Open(file.src)
+		switch {
+		case err == nil:
+		case os.IsNotExist(err):
+			return nil
+		default:
+			return
+		}
+		defer f.Close()
+		r = f
+	}
+
+	// Media files are already compressed
+	method := zip.Store
+	switch path.Ext(file.path) {
+	case ".css", ".js", ".svg", ".xml", ".webmanifest":
+		method = zip.Deflate
+	}
+	fw, err := zw.CreateHeader(&zip.FileHeader{
+		Name:   file.path,
+		Method: method,
+	})
+	if err != nil {
+		return
+	}
+	_, err = io.Copy(fw, r)
+	return
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "meguca/test"
+)
+
+func TestExportArchive(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "meguca-export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	samples := map[string]string{
+		"static/css/light.css": `a{background:url(/static/img/a.png)}` +
+			`@font-face{src:url("../fonts/b.woff?v=1")}` +
+			`b{background:url(data:image/png;base64,AAAA)}`,
+		"static/css/dark.css": `a{background:url('/static/img/a.png#x')}`,
+		"static/js/app.js":    "main()",
+		"static/img/a.png":    "png",
+		"static/fonts/b.woff": "woff",
+	}
+	for name, body := range samples {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(body), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	html := []byte(`<script src="/static/js/app.js"></script>` +
+		`<link href="/static/css/light.css">` +
+		`<a href="/static/css">` +
+		`<img src="/static/img/missing.png">`)
+	files, err := getExportAssets(dir, html)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html = staticLinkRe.ReplaceAllFunc(html, func(link []byte) []byte {
+		return link[1:]
+	})
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	if err := writeExport(zw, []byte("{}"), html, files); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := make(map[string]string, len(zr.File))
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries[f.Name] = string(b)
+	}
+
+	AssertDeepEquals(t, entries, map[string]string{
+		"thread.json": "{}",
+		"index.html": `<script src="static/js/app.js"></script>` +
+			`<link href="static/css/light.css">` +
+			`<a href="static/css">` +
+			`<img src="static/img/missing.png">`,
+		"static/css/light.css": `a{background:url(../img/a.png)}` +
+			`@font-face{src:url("../fonts/b.woff?v=1")}` +
+			`b{background:url(data:image/png;base64,AAAA)}`,
+		"static/css/dark.css": `a{background:url(../img/a.png#x)}`,
+		"static/js/app.js":    "main()",
+		"static/img/a.png":    "png",
+		"static/fonts/b.woff": "woff",
+	})
+}
@@ -0,0 +1,99 @@
+// Atom and RSS feed handlers
+
+package server
+
+import (
+	"net/http"
+
+	"meguca/cache"
+	"meguca/common"
+	"meguca/config"
+	"meguca/lang"
+	"meguca/templates"
+)
+
+// Absolute URL of the site the request was made to
+func requestRoot(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// Write an encoded feed to the client
+func serveFeed(w http.ResponseWriter, r *http.Request, buf []byte, atom bool) {
+	head := w.Header()
+	for key, val := range vanillaHeaders {
+		head.Set(key, val)
+	}
+	if assertCached(w, r, buf) {
+		return
+	}
+	if atom {
+		head.Set("Content-Type", "application/atom+xml; charset=utf-8")
+	} else {
+		head.Set("Content-Type", "application/rss+xml; charset=utf-8")
+	}
+	writeData(w, r, buf)
+}
+
+// Serves the newest threads of a board as a feed
+func boardFeed(w http.ResponseWriter, r *http.Request, atom bool) {
+	b := getParam(r, "board")
+	if !assertBoard(w, r, b) {
+		return
+	}
+	ss, _ := getSession(r, b)
+	if !assertNotModOnly(w, r, b, ss) {
+		return
+	}
+	if !checkRegisteredOnly(b, ss) {
+		text403(w, errOnlyRegistered)
+		return
+	}
+
+	l := lang.FromReq(r)
+	_, data, _, err := cache.GetJSONAndData(cache.BoardKey(l, b, 0, true), catalogCache)
+	if err != nil {
+		text500(w, r, err)
+		return
+	}
+
+	title := config.GetBoardConfig(b).Title
+	if b == "all" {
+		title = lang.Get(l, "aggregator")
+	}
+	buf, err := templates.BoardFeed(requestRoot(r), b, title, data.(common.Board), atom)
+	if err != nil {
+		text500(w, r, err)
+		return
+	}
+	serveFeed(w, r, buf, atom)
+}
+
+// Serves the newest replies to a thread as a feed
+func threadFeed(w http.ResponseWriter, r *http.Request, atom bool) {
+	ss, id, ok := validateThread(w, r)
+	if !ok {
+		return
+	}
+	if !checkRegisteredOnly(getParam(r, "board"), ss) {
+		text403(w, errOnlyRegistered)
+		return
+	}
+
+	k := cache.ThreadKey(lang.FromReq(r), id, common.NumPostsOnRequest)
+	_, data, _, err := cache.GetJSONAndData(k, threadCache)
+	if err != nil {
+		respondToJSONError(w, r, err)
+		return
+	}
+
+	buf, err := templates.ThreadFeed(requestRoot(r), data.(common.Thread), atom)
+	if err != nil {
+		text500(w, r, err)
+		return
+	}
+	serveFeed(w, r, buf, atom)
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"meguca/cache"
+	"meguca/config"
+	"meguca/db"
+)
+
+func TestFeeds(t *testing.T) {
+	cache.Clear()
+	assertTableClear(t, "boards")
+	(*config.Get()).DefaultLang = "en_GB"
+	for _, c := range [...]config.BoardConfig{
+		{BoardPublic: config.BoardPublic{ID: "a"}},
+		{BoardPublic: config.BoardPublic{ID: "m"}, ModOnly: true},
+		{BoardPublic: config.BoardPublic{ID: "r"}, IncludeAnon: true},
+	} {
+		if err := db.WriteBoard(nil, c); err != nil {
+			t.Fatal(err)
+		}
+		if err := config.SetBoardConfig(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeSampleThread(t)
+
+	const (
+		atom = "application/atom+xml; charset=utf-8"
+		rss  = "application/rss+xml; charset=utf-8"
+	)
+	cases := [...]struct {
+		name, url string
+		code      int
+		// Content type and root element of the feed
+		typ, root string
+		// Link to the feed's board or thread
+		link string
+	}{
+		{
+			"board atom", "/a/feed.atom", 200,
+			atom, "feed", "http://example.com/a/",
+		},
+		{
+			"board rss", "/a/feed.rss", 200,
+			rss, "rss", "http://example.com/a/",
+		},
+		{
+			"thread atom", "/a/1/feed.atom", 200,
+			atom, "feed", "http://example.com/a/1",
+		},
+		{
+			"thread rss", "/a/1/feed.rss", 200,
+			rss, "rss", "http://example.com/a/1",
+		},
+		{"nonexistent board", "/b/feed.atom", 404, "", "", ""},
+		{"nonexistent thread", "/a/22/feed.rss", 404, "", "", ""},
+		{"mod-only board", "/m/feed.atom", 404, "", "", ""},
+		{"registered-only board", "/r/feed.rss", 403, "", "", ""},
+	}
+
+	for i := range cases {
+		c := cases[i]
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec, req := newPair(c.url)
+			router.ServeHTTP(rec, req)
+			assertCode(t, rec, c.code)
+			if c.code != 200 {
+				return
+			}
+			assertHeaders(t, rec, map[string]string{
+				"Content-Type": c.typ,
+			})
+
+			var doc struct {
+				XMLName xml.Name
+			}
+			if err := xml.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+				t.Fatal(err)
+			}
+			if doc.XMLName.Local != c.root {
+				t.Errorf("unexpected root element: %s : %s",
+					c.root, doc.XMLName.Local)
+			}
+			if !strings.Contains(rec.Body.String(), c.link) {
+				t.Errorf("feed does not link %s", c.link)
+			}
+		})
+	}
+}
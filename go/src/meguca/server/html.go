@@ -80,6 +80,33 @@ func boardHTML(w http.ResponseWriter, r *http.Request, b string, catalog bool) {
 	serveHTML(w, r, html)
 }
 
+// Serves archived threads of a board
+func archiveHTML(w http.ResponseWriter, r *http.Request) {
+	b := getParam(r, "board")
+	if b == "all" {
+		serve404(w, r)
+		return
+	}
+	if !assertBoard(w, r, b) {
+		return
+	}
+	ss, _ := getSession(r, b)
+	if !assertNotModOnly(w, r, b, ss) {
+		return
+	}
+
+	l := lang.FromReq(r)
+	html, _, _, err := cache.GetHTML(cache.ArchiveKey(l, b), archiveCache)
+	if err != nil {
+		text500(w, r, err)
+		return
+	}
+
+	title := fmt.Sprintf("%s: %s", config.GetBoardConfig(b).Title, lang.Get(l, "archive"))
+	html = templates.Board(l, title, 0, 0, ss, true, html)
+	serveHTML(w, r, html)
+}
+
 // Asserts a thread exists on the specific board and renders the index template
 func threadHTML(w http.ResponseWriter, r *http.Request) {
 	ss, id, ok := validateThread(w, r)
@@ -96,9 +123,18 @@ func threadHTML(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Viewing a watched thread resets its unread post counter
+	if ss != nil {
+		if err := db.SeeWatchedThread(ss.UserID, id); err != nil {
+			text500(w, r, err)
+			return
+		}
+	}
+
 	b := getParam(r, "board")
-	title := data.(common.Thread).Subject
-	html = templates.Thread(id, l, b, title, lastN != 0, ss, html)
+	t := data.(common.Thread)
+	closed := t.Archived || t.Locked
+	html = templates.Thread(id, l, b, t.Subject, lastN != 0, closed, ss, html)
 	serveHTML(w, r, html)
 }
 
@@ -52,6 +52,7 @@ func createRouter(debugRoutes bool) http.Handler {
 	r.GET("/", redirectToDefault)
 	r.GET("/404.html", serve404)
 	r.GET("/stickers/", serveStickers)
+	r.GET("/search/", searchHTML)
 	r.GET("/:board/", func(w http.ResponseWriter, r *http.Request) {
 		boardHTML(w, r, getParam(r, "board"), false)
 	})
@@ -60,6 +61,20 @@ func createRouter(debugRoutes bool) http.Handler {
 	r.GET("/:board/catalog", func(w http.ResponseWriter, r *http.Request) {
 		boardHTML(w, r, getParam(r, "board"), true)
 	})
+	r.GET("/:board/archive", archiveHTML)
+	r.GET("/:board/banned", banHTML)
+	r.GET("/:board/feed.atom", func(w http.ResponseWriter, r *http.Request) {
+		boardFeed(w, r, true)
+	})
+	r.GET("/:board/feed.rss", func(w http.ResponseWriter, r *http.Request) {
+		boardFeed(w, r, false)
+	})
+	r.GET("/:board/:thread/feed.atom", func(w http.ResponseWriter, r *http.Request) {
+		threadFeed(w, r, true)
+	})
+	r.GET("/:board/:thread/feed.rss", func(w http.ResponseWriter, r *http.Request) {
+		threadFeed(w, r, false)
+	})
 	r.GET("/all/:id", crossRedirect)
 	r.GET("/all/catalog", func(w http.ResponseWriter, r *http.Request) {
 		boardHTML(w, r, "all", true)
@@ -81,6 +96,7 @@ func createRouter(debugRoutes bool) http.Handler {
 	api.GET("/smiles/:board", getBoardSmiles)
 	api.GET("/socket", websockets.Handler)
 	api.GET("/embed", serveEmbed)
+	api.GET("/search", serveSearchJSON)
 	// Idols.
 	/* 	api.GET("/idols/profiles", serveIdolProfiles)
 	   	api.POST("/idols/recognize", serveIdolRecognize)
@@ -88,11 +104,29 @@ func createRouter(debugRoutes bool) http.Handler {
 	/* 	api.GET("/idols/by-image/:id", serveImageInfo) */
 	// Posts.
 	api.GET("/post/:post", servePost)
+	api.GET("/post/:post/replies", servePostReplies)
 	api.POST("/post/token", createPostToken)
 	api.POST("/post", createPost)
+	api.PUT("/post/:post", editPost)
+	api.POST("/post/:post/self-delete", selfDeletePost)
 	api.POST("/post/react", reactToPost)
+	api.POST("/report", createReport)
+	api.POST("/appeal/:board", createAppeal)
 	api.POST("/thread", createThread)
 	api.GET("/thread/:thread/reacts", getTreadUserReaction)
+	api.GET("/thread/:thread/poll", servePoll)
+	api.GET("/thread/:thread/export", exportThread)
+	api.POST("/thread/:thread/watch", watchThread)
+	api.DELETE("/thread/:thread/watch", unwatchThread)
+	api.GET("/watched", serveWatched)
+	api.POST("/thread/:thread/poll", votePoll)
+	api.GET("/board/:board", func(w http.ResponseWriter, r *http.Request) {
+		serveBoardJSON(w, r, false)
+	})
+	api.GET("/board/:board/catalog", func(w http.ResponseWriter, r *http.Request) {
+		serveBoardJSON(w, r, true)
+	})
+	api.GET("/board/:board/archive", serveArchiveJSON)
 	// Account.
 	api.POST("/register", register)
 	api.POST("/login", login)
@@ -100,10 +134,26 @@ func createRouter(debugRoutes bool) http.Handler {
 	api.POST("/account/settings", serverSetAccountSettings)
 	api.POST("/logout", logout)
 	api.POST("/logout/all", logoutAll)
+	api.GET("/notifications", serveNotifications)
+	api.POST("/notifications/read", readNotifications)
 	// Mod.
 	api.POST("/ban", ban)
 	api.POST("/unban/:board", unban)
 	api.POST("/delete-post", deletePost)
+	api.POST("/undelete-post", undeletePost)
+	api.GET("/deleted-posts/:board", serveDeletedPosts)
+	api.GET("/same-ip/:id", getSameIPPosts)
+	api.POST("/nuke", nukePosts)
+	api.GET("/post/:post/edits", servePostEdits)
+	api.POST("/thread/sticky", setThreadSticky)
+	api.POST("/thread/lock", setThreadLocked)
+	api.POST("/thread/:thread/move", moveThread)
+	api.POST("/thread/:thread/merge", mergeThread)
+	api.GET("/reports/:board", serveReports)
+	api.POST("/reports/:board", resolveReports)
+	api.GET("/appeals/:board", serveAppeals)
+	api.POST("/appeals/:board", resolveAppeal)
+	api.GET("/mod-log/:board/export", exportModLog)
 	api.PUT("/boards/:board", assertBoardOwnerAPI(configureBoard))
 	api.POST("/smiles/:board", createSmile)
 	api.POST("/smiles/:board/rename", renameSmile)
@@ -115,6 +165,7 @@ func createRouter(debugRoutes bool) http.Handler {
 	// Too dangerous.
 	// api.POST("/delete-board", deleteBoard)
 	api.POST("/configure-server", configureServer)
+	api.POST("/notification", sendNotification)
 
 	// Partials.
 	// TODO(Kagami): Rewrite client to JSON API.
@@ -8,7 +8,10 @@ import (
 	"net/http"
 	"strconv"
 
+	"meguca/cache"
 	"meguca/common"
+	"meguca/config"
+	"meguca/lang"
 )
 
 const (
@@ -24,6 +27,11 @@ func serveJSON(w http.ResponseWriter, r *http.Request, data interface{}) {
 		text500(w, r, err)
 		return
 	}
+	serveRawJSON(w, r, buf)
+}
+
+// Write already encoded JSON to client.
+func serveRawJSON(w http.ResponseWriter, r *http.Request, buf []byte) {
 	head := w.Header()
 	for key, val := range vanillaHeaders {
 		head.Set(key, val)
@@ -35,6 +43,66 @@ func serveJSON(w http.ResponseWriter, r *http.Request, data interface{}) {
 	writeData(w, r, buf)
 }
 
+// Serve archived threads of a board as JSON
+func serveArchiveJSON(w http.ResponseWriter, r *http.Request) {
+	b := getParam(r, "board")
+	if b == "all" || !config.IsBoard(b) {
+		text400(w, errInvalidBoard)
+		return
+	}
+	ss, _ := getSession(r, b)
+	if !assertNotModOnlyAPI(w, b, ss) {
+		return
+	}
+
+	k := cache.ArchiveKey(lang.FromReq(r), b)
+	buf, _, _, err := cache.GetJSONAndData(k, archiveCache)
+	if err != nil {
+		text500(w, r, err)
+		return
+	}
+	serveRawJSON(w, r, buf)
+}
+
+// Serve a board index page or catalog as JSON along with the page count
+func serveBoardJSON(w http.ResponseWriter, r *http.Request, catalog bool) {
+	b := getParam(r, "board")
+	if !assertBoardAPI(w, b) {
+		return
+	}
+	ss, _ := getSession(r, b)
+	if !assertNotModOnlyAPI(w, b, ss) {
+		return
+	}
+
+	buf, data, _, err := cache.GetJSONAndData(boardCacheArgs(r, b, catalog))
+	switch err {
+	case nil:
+	case errPageOverflow:
+		serveErrorJSON(w, r, aerrPageOverflow)
+		return
+	default:
+		text500(w, r, err)
+		return
+	}
+
+	page, total := 0, 1
+	if !catalog {
+		p := data.(boardPage)
+		page = p.pageN
+		total = p.pageTotal
+	}
+	res := make([]byte, 0, len(buf)+64)
+	res = append(res, `{"page":`...)
+	res = strconv.AppendInt(res, int64(page), 10)
+	res = append(res, `,"pages":`...)
+	res = strconv.AppendInt(res, int64(total), 10)
+	res = append(res, `,"threads":`...)
+	res = append(res, buf...)
+	res = append(res, '}')
+	serveRawJSON(w, r, res)
+}
+
 // API helper. Returns standardly shaped error message.
 func serveErrorJSON(w http.ResponseWriter, r *http.Request, err error) {
 	// This function expects ApiError so assume any other values as bug.
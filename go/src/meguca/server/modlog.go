@@ -0,0 +1,145 @@
+// Moderation log export
+
+package server
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"meguca/auth"
+	"meguca/db"
+)
+
+// Layout of the from and to query parameters
+const modLogDateLayout = "2006-01-02"
+
+// Parse moderation log filters from the query string. Supported parameters
+// are by, type as a comma-separated list of action names and inclusive from
+// and to dates.
+func parseModLogFilter(q url.Values) (f db.ModLogFilter, err error) {
+	f.By = q.Get("by")
+	if s := q.Get("type"); s != "" {
+		for _, name := range strings.Split(s, ",") {
+			t, ok := auth.ParseModerationAction(strings.TrimSpace(name))
+			if !ok {
+				err = aerrInvalidLogFilter
+				return
+			}
+			f.Types = append(f.Types, t)
+		}
+	}
+	if s := q.Get("from"); s != "" {
+		f.From, err = time.Parse(modLogDateLayout, s)
+		if err != nil {
+			err = aerrInvalidLogFilter
+			return
+		}
+	}
+	if s := q.Get("to"); s != "" {
+		f.To, err = time.Parse(modLogDateLayout, s)
+		if err != nil {
+			err = aerrInvalidLogFilter
+			return
+		}
+		f.To = f.To.Add(24 * time.Hour)
+	}
+	return
+}
+
+// Serve the filtered moderation log of a board to its owners as a JSON or
+// CSV attachment
+func exportModLog(w http.ResponseWriter, r *http.Request) {
+	board := getParam(r, "board")
+	if _, ok := assertCanPerform(w, r, board, auth.BoardOwner); !ok {
+		return
+	}
+	q := r.URL.Query()
+	format := q.Get("format")
+	switch format {
+	case "":
+		format = "json"
+	case "json", "csv":
+	default:
+		serveErrorJSON(w, r, aerrInvalidFormat)
+		return
+	}
+	f, err := parseModLogFilter(q)
+	if err != nil {
+		serveErrorJSON(w, r, err)
+		return
+	}
+	log, err := db.GetModLog([]string{board}, f)
+	if err != nil {
+		serveErrorJSON(w, r, aerrInternal.Hide(err))
+		return
+	}
+
+	var buf []byte
+	if format == "json" {
+		buf, err = log.MarshalJSON()
+	} else {
+		buf, err = encodeModLogCSV(log)
+	}
+	if err != nil {
+		serveErrorJSON(w, r, aerrInternal.Hide(err))
+		return
+	}
+
+	head := w.Header()
+	if format == "json" {
+		head.Set("Content-Type", "application/json")
+	} else {
+		head.Set("Content-Type", "text/csv; charset=utf-8")
+	}
+	head.Set("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="%s-mod-log.%s"`, board, format))
+	writeData(w, r, buf)
+}
+
+func encodeModLogCSV(log auth.ModLogRecords) ([]byte, error) {
+	var buf bytes.Buffer
+	cw := csv.NewWriter(&buf)
+	cw.Write([]string{
+		"created", "board", "id", "action", "by", "reason", "duration",
+		"postBody", "postFiles", "postIDs",
+	})
+	for _, rec := range log {
+		var duration string
+		if rec.Duration != 0 {
+			duration = strconv.FormatInt(rec.Duration, 10)
+		}
+		ids := make([]string, len(rec.PostIDs))
+		for i, id := range rec.PostIDs {
+			ids[i] = strconv.FormatUint(id, 10)
+		}
+		cw.Write([]string{
+			time.Unix(rec.Created, 0).UTC().Format(time.RFC3339),
+			escapeCSVCell(rec.Board),
+			strconv.FormatUint(rec.ID, 10),
+			rec.Type.String(),
+			escapeCSVCell(rec.By),
+			escapeCSVCell(rec.Reason),
+			duration,
+			escapeCSVCell(rec.PostBody),
+			escapeCSVCell(strings.Join(rec.PostFiles, " ")),
+			strings.Join(ids, " "),
+		})
+	}
+	cw.Flush()
+	return buf.Bytes(), cw.Error()
+}
+
+// Prevent spreadsheet applications from evaluating user-supplied cells as
+// formulas
+func escapeCSVCell(s string) string {
+	if s != "" && strings.IndexByte("=+-@", s[0]) != -1 {
+		return "'" + s
+	}
+	return s
+}
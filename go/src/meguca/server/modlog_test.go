@@ -0,0 +1,70 @@
+package server
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"meguca/auth"
+	. "meguca/test"
+)
+
+func TestEncodeModLogCSV(t *testing.T) {
+	t.Parallel()
+
+	buf, err := encodeModLogCSV(auth.ModLogRecords{
+		{
+			Board:     "a",
+			ID:        1,
+			Type:      auth.BanPost,
+			By:        "@admin",
+			Created:   1,
+			Reason:    `=HYPERLINK("http://example.com")`,
+			Duration:  -1,
+			PostBody:  "+1",
+			PostFiles: []string{"-foo.png"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(bytes.NewReader(buf)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("unexpected row count: %d", len(rows))
+	}
+	row := rows[1]
+	AssertDeepEquals(t,
+		[]string{row[1], row[4], row[5], row[6], row[7], row[8]},
+		[]string{
+			"a",
+			"'@admin",
+			`'=HYPERLINK("http://example.com")`,
+			"-1",
+			"'+1",
+			"'-foo.png",
+		})
+}
+
+func TestEscapeCSVCell(t *testing.T) {
+	t.Parallel()
+
+	cases := [...]struct {
+		in, out string
+	}{
+		{"", ""},
+		{"foo", "foo"},
+		{"a=b", "a=b"},
+		{"=1+1", "'=1+1"},
+		{"+1", "'+1"},
+		{"-1", "'-1"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+	}
+	for _, c := range cases {
+		if s := escapeCSVCell(c.in); s != c.out {
+			t.Errorf("%q: expected %q, got %q", c.in, c.out, s)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Reply notification inbox handlers
+
+package server
+
+import (
+	"net/http"
+
+	"meguca/auth"
+	"meguca/db"
+)
+
+type notificationsResponse struct {
+	Unread        int                `json:"unread"`
+	Notifications auth.Notifications `json:"notifications"`
+}
+
+// Serve the latest reply notifications of the logged in account
+func serveNotifications(w http.ResponseWriter, r *http.Request) {
+	ss := assertSession(w, r, "")
+	if ss == nil {
+		return
+	}
+	ns, unread, err := db.GetNotifications(ss.UserID)
+	if err != nil {
+		serveErrorJSON(w, r, aerrInternal.Hide(err))
+		return
+	}
+	serveJSON(w, r, notificationsResponse{unread, ns})
+}
+
+// Mark notifications as read. An empty ID list marks all of them.
+func readNotifications(w http.ResponseWriter, r *http.Request) {
+	ss := assertSession(w, r, "")
+	if ss == nil {
+		return
+	}
+	var req struct {
+		IDs []uint64 `json:"ids"`
+	}
+	if err := readJSON(r, &req); err != nil {
+		serveErrorJSON(w, r, err)
+		return
+	}
+	if err := db.ReadNotifications(ss.UserID, req.IDs); err != nil {
+		serveErrorJSON(w, r, aerrInternal.Hide(err))
+		return
+	}
+	serveEmptyJSON(w, r)
+}
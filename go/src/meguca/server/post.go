@@ -14,6 +14,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"meguca/assets"
 	"meguca/auth"
@@ -21,6 +23,7 @@ import (
 	"meguca/config"
 	"meguca/db"
 	"meguca/feeds"
+	"meguca/parser"
 	"meguca/websockets"
 )
 
@@ -54,6 +57,48 @@ func servePost(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Serve links to all posts replying to a post as JSON
+func servePostReplies(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(getParam(r, "post"), 10, 64)
+	if err != nil {
+		text400(w, err)
+		return
+	}
+
+	board, _, err := db.GetPostParenthood(id)
+	switch err {
+	case nil:
+	case sql.ErrNoRows:
+		serve404(w, r)
+		return
+	default:
+		respondToJSONError(w, r, err)
+		return
+	}
+	ss, _ := getSession(r, board)
+	if !assertNotModOnly(w, r, board, ss) {
+		return
+	}
+
+	bls, err := db.GetBacklinks(nil, []uint64{id})
+	if err != nil {
+		respondToJSONError(w, r, err)
+		return
+	}
+	// Don't leak replies from mod-only boards.
+	replies := make(common.Links, 0, len(bls[id]))
+	for _, l := range bls[id] {
+		if l.Board != board && config.IsModOnlyBoard(l.Board) {
+			lss, _ := getSession(r, l.Board)
+			if !checkModOnly(l.Board, lss) {
+				continue
+			}
+		}
+		replies = append(replies, l)
+	}
+	serveJSON(w, r, replies)
+}
+
 func getHashedHeaders(r *http.Request) string {
 	str := strings.Join(r.Header["User-Agent"][:], "")
 	str += strings.Join(r.Header["Accept"], "")
@@ -71,6 +116,11 @@ func getHashedHeaders(r *http.Request) string {
 	return base64.URLEncoding.EncodeToString(hasher.Sum(nil))
 }
 
+// Unique ID of the client stored in posts and matched against bans
+func getUniqueID(r *http.Request) string {
+	return getHashedHeaders(r)[:10]
+}
+
 // Client should get token and solve challenge in order to post.
 func createPostToken(w http.ResponseWriter, r *http.Request) {
 	ip, err := auth.GetIP(r)
@@ -137,14 +187,38 @@ func createThread(w http.ResponseWriter, r *http.Request) {
 		PostCreationRequest: postReq,
 		Subject:             subject,
 	}
+	if q := r.Form.Get("pollQuestion"); q != "" {
+		var duration int
+		if d := r.Form.Get("pollDuration"); d != "" {
+			var err error
+			duration, err = strconv.Atoi(d)
+			if err != nil {
+				text400(w, err)
+				return
+			}
+		}
+		poll, err := parser.ParsePoll(
+			q,
+			r.Form["pollOptions[]"],
+			r.Form.Get("pollMultiple") == "on",
+			duration,
+		)
+		if err != nil {
+			text400(w, err)
+			return
+		}
+		req.Poll = poll
+	}
 
 	post, err := websockets.CreateThread(req)
 	if err != nil {
-		// TODO(Kagami): Not all errors are 400.
-		// TODO(Kagami): Write JSON errors instead.
-		text400(w, err)
+		servePostCreationError(w, r, err)
 		return
 	}
+	if err := feeds.InsertBacklinks(post.StandalonePost); err != nil {
+		logError(r, err)
+	}
+	feeds.SendNotifications(post.ID)
 
 	res := map[string]uint64{"id": post.ID}
 	serveJSON(w, r, res)
@@ -513,6 +587,18 @@ func getValidSmileName(smileName string, board string) (newName string, err erro
 	return newName, nil
 }
 
+// Respond with the error of failed thread or post creation
+func servePostCreationError(w http.ResponseWriter, r *http.Request, err error) {
+	switch err {
+	case websockets.ErrCaptchaRequired:
+		serveErrorJSON(w, r, aerrCaptchaRequired)
+	default:
+		// TODO(Kagami): Not all errors are 400.
+		// TODO(Kagami): Write JSON errors instead.
+		text400(w, err)
+	}
+}
+
 // Create post.
 func createPost(w http.ResponseWriter, r *http.Request) {
 	req, ok := parsePostCreationForm(w, r)
@@ -538,21 +624,317 @@ func createPost(w http.ResponseWriter, r *http.Request) {
 	}
 
 	post, msg, err := websockets.CreatePost(req, op)
+	if err != nil {
+		servePostCreationError(w, r, err)
+		return
+	}
+	// Held posts are only propagated once approved
+	held := post.HoldReason != ""
+	if !held {
+		feeds.InsertPostInto(post.StandalonePost, msg)
+		if err := feeds.InsertBacklinks(post.StandalonePost); err != nil {
+			logError(r, err)
+		}
+		feeds.SendNotifications(post.ID)
+		feeds.UpdateWatchers(op)
+	}
+
+	serveJSON(w, r, struct {
+		ID   uint64 `json:"id"`
+		Held bool   `json:"held,omitempty"`
+	}{post.ID, held})
+}
+
+type postEditRequest struct {
+	Body  string `json:"body"`
+	Token string `json:"token"`
+}
+
+// Rewrite the body of an own post within the board's editing window
+func editPost(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(getParam(r, "post"), 10, 64)
 	if err != nil {
 		text400(w, err)
 		return
 	}
-	feeds.InsertPostInto(post.StandalonePost, msg)
+	var req postEditRequest
+	if err := readJSON(r, &req); err != nil {
+		serveErrorJSON(w, r, err)
+		return
+	}
 
-	res := map[string]uint64{"id": post.ID}
-	serveJSON(w, r, res)
+	p, err := db.GetPostAuthorInfo(id)
+	switch err {
+	case nil:
+	case sql.ErrNoRows:
+		serveErrorJSON(w, r, aerrNoPost)
+		return
+	default:
+		text500(w, r, err)
+		return
+	}
+	ip, allowed := assertNotBannedAPI(w, r, p.Board)
+	if !allowed {
+		return
+	}
+
+	// Named posts are matched by account, anonymous ones by the token
+	// they were created with.
+	ss, _ := getSession(r, p.Board)
+	isAuthor := ss != nil && p.UserID != "" && p.UserID == ss.UserID
+	if !isAuthor {
+		isAuthor = req.Token != "" && req.Token == p.Token && ip == p.IP
+	}
+	if !isAuthor {
+		serveErrorJSON(w, r, aerrNotAuthor)
+		return
+	}
+
+	window := config.GetBoardConfig(p.Board).EditWindow
+	if window <= 0 {
+		window = common.DefaultEditWindow
+	}
+	if time.Now().Unix()-p.Time > int64(window) {
+		serveErrorJSON(w, r, aerrEditExpired)
+		return
+	}
+	state, err := db.GetThreadState(nil, p.OP)
+	if err != nil {
+		text500(w, r, err)
+		return
+	}
+	if state.Closed() {
+		serveErrorJSON(w, r, aerrThreadClosed)
+		return
+	}
+
+	body := strings.Replace(req.Body, "\r\n", "\n", -1)
+	body, hit := config.FilterWords(p.Board, body)
+	// Threads can't be hidden until approved
+	if hit != nil && (hit.Mode == config.FilterReject || id == p.OP) {
+		serveErrorJSON(w, r, aerrFilteredPhrase)
+		return
+	}
+	if utf8.RuneCountInString(body) > common.MaxLenBody {
+		serveErrorJSON(w, r, aerrBodyTooLong)
+		return
+	}
+	if strings.Count(body, "\n") > common.MaxLinesBody {
+		serveErrorJSON(w, r, aerrTooManyLines)
+		return
+	}
+	links, commands, err := parser.ParseBody([]byte(body), p.Board)
+	if err != nil {
+		text400(w, err)
+		return
+	}
+
+	err = db.EditPost(id, body, links, commands)
+	if err != nil {
+		text500(w, r, err)
+		return
+	}
+	if hit != nil {
+		err = db.HoldPost(nil, id, p.Board, hit.HoldReason())
+		if err != nil {
+			text500(w, r, err)
+			return
+		}
+		err = feeds.DeletePost(id, p.OP)
+	} else {
+		err = feeds.EditPost(id, p.OP, body, links, commands)
+	}
+	if err != nil {
+		text500(w, r, err)
+		return
+	}
+	serveEmptyJSON(w, r)
+}
+
+// Delete an own post by session or deletion password
+func selfDeletePost(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(getParam(r, "post"), 10, 64)
+	if err != nil {
+		text400(w, err)
+		return
+	}
+	var req struct {
+		Password string `json:"password"`
+	}
+	if err := readJSON(r, &req); err != nil {
+		serveErrorJSON(w, r, err)
+		return
+	}
+
+	p, err := db.GetPostAuthorInfo(id)
+	switch err {
+	case nil:
+	case sql.ErrNoRows:
+		serveErrorJSON(w, r, aerrNoPost)
+		return
+	default:
+		text500(w, r, err)
+		return
+	}
+	if id == p.OP {
+		serveErrorJSON(w, r, aerrNoThreadDelete)
+		return
+	}
+
+	// Anonymous authors are logged as "self"
+	by := "self"
+	ss, _ := getSession(r, p.Board)
+	if ss != nil && p.UserID != "" && p.UserID == ss.UserID {
+		by = ss.UserID
+	} else if req.Password == "" || p.Password == nil ||
+		auth.BcryptCompare(req.Password, p.Password) != nil {
+		serveErrorJSON(w, r, aerrNotAuthor)
+		return
+	}
+
+	state, err := db.GetThreadState(nil, p.OP)
+	if err != nil {
+		text500(w, r, err)
+		return
+	}
+	if state.Closed() {
+		serveErrorJSON(w, r, aerrThreadClosed)
+		return
+	}
+
+	err = db.SelfDeletePost(id, by)
+	if err != nil {
+		text500(w, r, err)
+		return
+	}
+	serveEmptyJSON(w, r)
+}
+
+type pollVoteRequest struct {
+	Options []int64 `json:"options"`
+}
+
+// Read the poll of a thread and the board it belongs to. ok = false if
+// failed and caller should return.
+func getThreadPoll(w http.ResponseWriter, r *http.Request) (
+	op uint64, board string, poll *common.Poll, ok bool,
+) {
+	op, err := strconv.ParseUint(getParam(r, "thread"), 10, 64)
+	if err != nil {
+		text400(w, err)
+		return
+	}
+	board, err = db.GetPostBoard(op)
+	switch err {
+	case nil:
+	case sql.ErrNoRows:
+		serve404(w, r)
+		return
+	default:
+		text500(w, r, err)
+		return
+	}
+	ss, _ := getSession(r, board)
+	if !assertNotModOnlyAPI(w, board, ss) {
+		return
+	}
+	poll, err = db.GetPoll(nil, op)
+	if err != nil {
+		text500(w, r, err)
+		return
+	}
+	if poll == nil {
+		serveErrorJSON(w, r, aerrNoPoll)
+		return
+	}
+	ok = true
+	return
+}
+
+// Serve a thread poll with its current tallies
+func servePoll(w http.ResponseWriter, r *http.Request) {
+	_, _, poll, ok := getThreadPoll(w, r)
+	if !ok {
+		return
+	}
+	serveJSON(w, r, poll)
+}
+
+// Vote in a thread poll. Only one vote per IP is allowed.
+func votePoll(w http.ResponseWriter, r *http.Request) {
+	op, board, poll, ok := getThreadPoll(w, r)
+	if !ok {
+		return
+	}
+	var req pollVoteRequest
+	if err := readJSON(r, &req); err != nil {
+		serveErrorJSON(w, r, err)
+		return
+	}
+	ip, allowed := assertNotBannedAPI(w, r, board)
+	if !allowed {
+		return
+	}
+
+	state, err := db.GetThreadState(nil, op)
+	if err != nil {
+		text500(w, r, err)
+		return
+	}
+	if state.Closed() {
+		serveErrorJSON(w, r, aerrThreadClosed)
+		return
+	}
+	if poll.Expired() {
+		serveErrorJSON(w, r, aerrPollExpired)
+		return
+	}
+
+	n := len(req.Options)
+	if n == 0 || (!poll.Multiple && n > 1) {
+		serveErrorJSON(w, r, aerrInvalidVote)
+		return
+	}
+	seen := make(map[int64]bool, n)
+	for _, o := range req.Options {
+		if o < 0 || o >= int64(len(poll.Options)) || seen[o] {
+			serveErrorJSON(w, r, aerrInvalidVote)
+			return
+		}
+		seen[o] = true
+	}
+
+	// Votes are keyed by IP even for logged in users, so switching between
+	// an account and anonymous voting can't be used to vote twice
+	err = db.InsertPollVote(op, ip, req.Options)
+	switch {
+	case err == nil:
+	case db.IsConflictError(err):
+		serveErrorJSON(w, r, aerrAlreadyVoted)
+		return
+	default:
+		text500(w, r, err)
+		return
+	}
+
+	poll.Votes, err = db.GetPollVotes(nil, op, len(poll.Options))
+	if err != nil {
+		text500(w, r, err)
+		return
+	}
+	err = feeds.UpdatePoll(op, poll.Votes)
+	if err != nil {
+		text500(w, r, err)
+		return
+	}
+	serveJSON(w, r, poll)
 }
 
 // ok = false if failed and caller should return.
 func parsePostCreationForm(w http.ResponseWriter, r *http.Request) (
 	req websockets.PostCreationRequest, ok bool,
 ) {
-	uniqueID := getHashedHeaders(r)[:10]
+	uniqueID := getUniqueID(r)
 
 	f, m, err := parseUploadForm(w, r)
 	if err != nil {
@@ -632,7 +1014,14 @@ func parsePostCreationForm(w http.ResponseWriter, r *http.Request) (
 		Sign:         f.Get("sign"),
 		ShowBadge:    f.Get("showBadge") == "on" || modOnly,
 		ShowName:     modOnly,
+		Sage:         formBool(f, "sage"),
+		Password:     f.Get("password"),
+		Name:         f.Get("name"),
 		Session:      ss,
+		Captcha: auth.Captcha{
+			CaptchaID: f.Get("captchaID"),
+			Solution:  f.Get("captchaSolution"),
+		},
 	}
 	ok = true
 	return
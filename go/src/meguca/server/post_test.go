@@ -0,0 +1,168 @@
+package server
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"meguca/common"
+	"meguca/config"
+	"meguca/db"
+	. "meguca/test"
+)
+
+// Write board a with thread 1 and reply 2 linking it and mod-only board m
+// with thread 3 linking post 1
+func writeBacklinkSample(t *testing.T) {
+	assertTableClear(t, "boards")
+	for _, c := range [...]config.BoardConfig{
+		{BoardPublic: config.BoardPublic{ID: "a"}},
+		{BoardPublic: config.BoardPublic{ID: "m"}, ModOnly: true},
+	} {
+		if err := db.WriteBoard(nil, c); err != nil {
+			t.Fatal(err)
+		}
+		if err := config.SetBoardConfig(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	post := func(id, op uint64, board string, links common.Links) db.Post {
+		return db.Post{
+			StandalonePost: common.StandalonePost{
+				Post: common.Post{
+					ID:    id,
+					Time:  time.Now().Unix(),
+					Links: links,
+				},
+				OP:    op,
+				Board: board,
+			},
+		}
+	}
+	link := common.Links{{ID: 1, OP: 1, Board: "a"}}
+	if err := db.InsertThread(nil, post(1, 1, "a", nil), "s"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.InsertPost(nil, post(2, 1, "a", link)); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.InsertThread(nil, post(3, 3, "m", link), "s"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServePostReplies(t *testing.T) {
+	writeBacklinkSample(t)
+
+	cases := [...]struct {
+		name, id string
+		code     int
+		body     string
+	}{
+		{"invalid post ID", "www", 400, ""},
+		{"nonexistent post", "99", 404, ""},
+		{"post on mod-only board", "3", 404, ""},
+		{"no replies", "2", 200, "[]"},
+		{"replies from mod-only boards hidden", "1", 200, `[[2,1,"a"]]`},
+	}
+
+	for i := range cases {
+		c := cases[i]
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec, req := newPair("/api/post/" + c.id + "/replies")
+			router.ServeHTTP(rec, req)
+			assertCode(t, rec, c.code)
+			if c.code == 200 {
+				assertBody(t, rec, c.body)
+			}
+		})
+	}
+}
+
+func TestEditPost(t *testing.T) {
+	assertTableClear(t, "boards")
+	c := config.BoardConfig{
+		BoardPublic: config.BoardPublic{ID: "a"},
+	}
+	if err := db.WriteBoard(nil, c); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SetBoardConfig(c); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().Unix()
+	post := func(id uint64, created int64) db.Post {
+		return db.Post{
+			StandalonePost: common.StandalonePost{
+				Post: common.Post{
+					ID:   id,
+					Time: created,
+					Body: "foo",
+				},
+				OP:    1,
+				Board: "a",
+			},
+			// Address of httptest requests
+			IP:    "192.0.2.1",
+			Token: "token",
+		}
+	}
+	if err := db.InsertThread(nil, post(1, now), "s"); err != nil {
+		t.Fatal(err)
+	}
+	expired := now - common.DefaultEditWindow - 60
+	for _, p := range [...]db.Post{post(2, now), post(3, expired)} {
+		if err := db.InsertPost(nil, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Run sequentially, as successful edits change post 2
+	cases := [...]struct {
+		name, id, token string
+		code            int
+		// Body of post 2 after the request
+		body string
+	}{
+		{"invalid post ID", "www", "token", 400, "foo"},
+		{"nonexistent post", "99", "token", 404, "foo"},
+		{"wrong token", "2", "other", 403, "foo"},
+		{"editing window expired", "3", "token", 403, "foo"},
+		{"edited", "2", "token", 200, "bar"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest("PUT", "/api/post/"+c.id,
+				encodeBody(t, postEditRequest{
+					Body:  "bar",
+					Token: c.token,
+				}))
+			router.ServeHTTP(rec, req)
+			assertCode(t, rec, c.code)
+
+			p, err := db.GetPost(2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Body != c.body {
+				LogUnexpected(t, c.body, p.Body)
+			}
+		})
+	}
+
+	edits, err := db.GetPostEdits(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	history := make([]string, len(edits))
+	for i, e := range edits {
+		history[i] = e.Body
+	}
+	AssertDeepEquals(t, history, []string{"foo"})
+}
@@ -0,0 +1,175 @@
+// Post reporting and moderator report queue handlers
+
+package server
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"meguca/auth"
+	"meguca/common"
+	"meguca/db"
+)
+
+type reportRequest struct {
+	ID     uint64 `json:"id"`
+	Reason string `json:"reason"`
+	auth.Captcha
+}
+
+type resolveReportsRequest struct {
+	IDs      []uint64 `json:"ids"`
+	Action   string   `json:"action"`
+	Duration uint64   `json:"duration"`
+	Reason   string   `json:"reason"`
+}
+
+// Report a post to the moderators of its board
+func createReport(w http.ResponseWriter, r *http.Request) {
+	var req reportRequest
+	if err := readJSON(r, &req); err != nil {
+		serveErrorJSON(w, r, err)
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" ||
+		utf8.RuneCountInString(req.Reason) > common.MaxLenReportReason {
+		serveErrorJSON(w, r, aerrInvalidReport)
+		return
+	}
+
+	board, err := db.GetPostBoard(req.ID)
+	switch err {
+	case nil:
+	case sql.ErrNoRows:
+		serveErrorJSON(w, r, aerrNoPost)
+		return
+	default:
+		serveErrorJSON(w, r, aerrInternal.Hide(err))
+		return
+	}
+	ss, _ := getSession(r, board)
+	if !assertNotModOnlyAPI(w, board, ss) {
+		return
+	}
+	ip, ok := assertNotBannedAPI(w, r, board)
+	if !ok {
+		return
+	}
+
+	// Anonymous reporters must solve a captcha
+	if ss == nil && !auth.AuthenticateCaptcha(req.Captcha) {
+		serveErrorJSON(w, r, aerrInvalidCaptcha)
+		return
+	}
+	can, err := db.CanReport(ip)
+	switch {
+	case err != nil:
+		serveErrorJSON(w, r, aerrInternal.Hide(err))
+		return
+	case !can:
+		serveErrorJSON(w, r, aerrTooManyReports)
+		return
+	}
+
+	err = db.InsertReport(req.ID, board, req.Reason, ip)
+	switch {
+	case err == nil:
+		serveEmptyJSON(w, r)
+	case db.IsConflictError(err):
+		serveErrorJSON(w, r, aerrAlreadyReported)
+	default:
+		serveErrorJSON(w, r, aerrInternal.Hide(err))
+	}
+}
+
+// Serve the open reports of a board to its staff
+func serveReports(w http.ResponseWriter, r *http.Request) {
+	board := getParam(r, "board")
+	if _, ok := assertCanPerform(w, r, board, auth.Janitor); !ok {
+		return
+	}
+	reports, err := db.GetReports(board)
+	if err != nil {
+		serveErrorJSON(w, r, aerrInternal.Hide(err))
+		return
+	}
+	serveJSON(w, r, reports)
+}
+
+// Dismiss reported posts or act on them by approving held posts, deleting
+// the posts or banning their authors. Either way the reports are closed.
+func resolveReports(w http.ResponseWriter, r *http.Request) {
+	board := getParam(r, "board")
+	ss, ok := assertCanPerform(w, r, board, auth.Janitor)
+	if !ok {
+		return
+	}
+	var req resolveReportsRequest
+	if err := readJSON(r, &req); err != nil {
+		serveErrorJSON(w, r, err)
+		return
+	}
+	posts, err := db.GetReportedPosts(board, req.IDs)
+	if err != nil {
+		serveErrorJSON(w, r, aerrInternal.Hide(err))
+		return
+	}
+
+	switch req.Action {
+	case "dismiss":
+	case "approve":
+		ok := moderatePostIDs(w, r, posts, auth.Moderator,
+			func(id uint64, userID string) error {
+				// Reported posts, that are not held, stay as they are
+				err := db.ApprovePost(id, userID)
+				if err == sql.ErrNoRows {
+					return nil
+				}
+				return err
+			})
+		if !ok {
+			return
+		}
+	case "delete":
+		if !moderatePostIDs(w, r, posts, auth.Moderator, db.DeletePost) {
+			return
+		}
+	case "ban":
+		switch {
+		case req.Reason == "", len(req.Reason) > common.MaxBanReasonLength:
+			serveErrorJSON(w, r, aerrInvalidReason)
+			return
+		case req.Duration == 0:
+			text400(w, errNoDuration)
+			return
+		}
+		expires := time.Now().Add(time.Duration(req.Duration) * time.Minute)
+		ok := moderatePostIDs(w, r, posts, auth.Moderator,
+			func(id uint64, userID string) error {
+				ips, err := db.Ban(board, req.Reason, userID, expires,
+					auth.BanOptions{}, id)
+				if err != nil {
+					return err
+				}
+				redirectBanned(board, ips)
+				return nil
+			})
+		if !ok {
+			return
+		}
+	default:
+		serveErrorJSON(w, r, aerrInvalidAction)
+		return
+	}
+
+	err = db.ResolveReports(board, posts, ss.UserID)
+	if err != nil {
+		serveErrorJSON(w, r, aerrInternal.Hide(err))
+		return
+	}
+	serveEmptyJSON(w, r)
+}
@@ -0,0 +1,151 @@
+// Full-text search handlers
+
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"meguca/common"
+	"meguca/config"
+	"meguca/db"
+	"meguca/lang"
+	"meguca/templates"
+)
+
+// Read search parameters from the URL query. Returns an ApiError, if they
+// are invalid.
+func parseSearchParams(r *http.Request, q url.Values) (
+	p db.SearchParams, err error,
+) {
+	p.Query = strings.TrimSpace(q.Get("q"))
+	if p.Query == "" {
+		err = aerrNoSearchQuery
+		return
+	}
+	if utf8.RuneCountInString(p.Query) > common.MaxLenSearchQuery {
+		err = aerrSearchTooLong
+		return
+	}
+
+	// Search all visible boards, unless a specific one is requested.
+	// Mod-only boards are only included for their moderators.
+	boards := config.GetAllBoardIDs()
+	if b := q.Get("board"); b != "" && b != "all" {
+		if !config.IsBoard(b) {
+			err = aerrInvalidSearch
+			return
+		}
+		boards = []string{b}
+	}
+	p.Boards = make([]string, 0, len(boards))
+	for _, b := range boards {
+		if b == "all" {
+			continue
+		}
+		if config.IsModOnlyBoard(b) {
+			ss, _ := getSession(r, b)
+			if !checkModOnly(b, ss) {
+				continue
+			}
+		}
+		p.Boards = append(p.Boards, b)
+	}
+
+	if p.From, err = parseSearchDate(q.Get("from")); err != nil {
+		return
+	}
+	if p.To, err = parseSearchDate(q.Get("to")); err != nil {
+		return
+	}
+	// Include the whole end day.
+	if p.To != 0 {
+		p.To += 24 * 60 * 60
+	}
+
+	switch q.Get("files") {
+	case "", "0", "false":
+	default:
+		p.HasFiles = true
+	}
+
+	p.Author = q.Get("author")
+	if p.Author != "" && !checkUserID(p.Author) {
+		err = aerrInvalidSearch
+		return
+	}
+
+	if s := q.Get("page"); s != "" {
+		var page uint64
+		page, err = strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			err = aerrInvalidSearch
+			return
+		}
+		p.Page = int(page)
+	}
+	return
+}
+
+// Parse a YYYY-MM-DD date into Unix time of its UTC midnight
+func parseSearchDate(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return 0, aerrInvalidSearch
+	}
+	return t.Unix(), nil
+}
+
+// Serve full-text search results as JSON
+func serveSearchJSON(w http.ResponseWriter, r *http.Request) {
+	p, err := parseSearchParams(r, r.URL.Query())
+	if err != nil {
+		serveErrorJSON(w, r, err)
+		return
+	}
+	posts, more, err := db.SearchPosts(p)
+	if err != nil {
+		serveErrorJSON(w, r, aerrInternal.Hide(err))
+		return
+	}
+	serveJSON(w, r, struct {
+		Posts []common.SearchResult `json:"posts"`
+		Page  int                   `json:"page"`
+		More  bool                  `json:"more"`
+	}{posts, p.Page, more})
+}
+
+// Render the search form with results for noscript clients
+func searchHTML(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var (
+		posts []common.SearchResult
+		more  bool
+		page  int
+	)
+	if q.Get("q") != "" {
+		p, err := parseSearchParams(r, q)
+		if err != nil {
+			text400(w, err)
+			return
+		}
+		posts, more, err = db.SearchPosts(p)
+		if err != nil {
+			text500(w, r, err)
+			return
+		}
+		page = p.Page
+	}
+
+	ss, _ := getSession(r, "")
+	l := lang.FromReq(r)
+	html := templates.Search(ss, l, q, posts, page, more)
+	serveHTML(w, r, html)
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"meguca/config"
+	"meguca/db"
+	. "meguca/test"
+)
+
+func TestParseSearchParams(t *testing.T) {
+	for _, b := range config.GetAllBoardIDs() {
+		config.RemoveBoard(b)
+	}
+	for _, c := range [...]config.BoardConfig{
+		{BoardPublic: config.BoardPublic{ID: "a"}},
+		{BoardPublic: config.BoardPublic{ID: "c"}},
+		{BoardPublic: config.BoardPublic{ID: "m"}, ModOnly: true},
+	} {
+		if err := config.SetBoardConfig(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	from := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC).Unix()
+	cases := [...]struct {
+		name, query string
+		err         error
+		p           db.SearchParams
+	}{
+		{
+			name:  "no query",
+			query: "q=+&board=a",
+			err:   aerrNoSearchQuery,
+		},
+		{
+			name:  "query too long",
+			query: "q=" + strings.Repeat("a", 201),
+			err:   aerrSearchTooLong,
+		},
+		{
+			name:  "all visible boards",
+			query: "q=foo",
+			p: db.SearchParams{
+				Query:  "foo",
+				Boards: []string{"a", "c"},
+			},
+		},
+		{
+			name:  "single board",
+			query: "q=foo&board=c",
+			p: db.SearchParams{
+				Query:  "foo",
+				Boards: []string{"c"},
+			},
+		},
+		{
+			name:  "nonexistent board",
+			query: "q=foo&board=x",
+			err:   aerrInvalidSearch,
+		},
+		{
+			name:  "mod-only board",
+			query: "q=foo&board=m",
+			p: db.SearchParams{
+				Query:  "foo",
+				Boards: []string{},
+			},
+		},
+		{
+			name: "all filters",
+			query: "q=foo&from=2020-01-02&to=2020-01-02&files=1" +
+				"&author=bar&page=2",
+			p: db.SearchParams{
+				Query:    "foo",
+				Boards:   []string{"a", "c"},
+				From:     from,
+				To:       from + 24*60*60,
+				HasFiles: true,
+				Author:   "bar",
+				Page:     2,
+			},
+		},
+		{
+			name:  "invalid date",
+			query: "q=foo&from=02.01.2020",
+			err:   aerrInvalidSearch,
+		},
+		{
+			name:  "invalid author",
+			query: "q=foo&author=%20",
+			err:   aerrInvalidSearch,
+		},
+		{
+			name:  "invalid page",
+			query: "q=foo&page=-1",
+			err:   aerrInvalidSearch,
+		},
+	}
+
+	for i := range cases {
+		c := cases[i]
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			q, err := url.ParseQuery(c.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, err := parseSearchParams(newRequest("/api/search?"+c.query), q)
+			if err != c.err {
+				UnexpectedError(t, err)
+			}
+			if c.err == nil {
+				AssertDeepEquals(t, p, c.p)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"runtime/debug"
+	"strconv"
 	"syscall"
 
 	"meguca/auth"
@@ -98,3 +99,14 @@ func parseUploadForm(w http.ResponseWriter, r *http.Request) (
 	m = r.MultipartForm
 	return
 }
+
+// Read a boolean form field. Accepts both checkbox values and the "true" and
+// "false" literals sent by JSON-minded API clients.
+func formBool(f url.Values, key string) bool {
+	v := f.Get(key)
+	if v == "on" {
+		return true
+	}
+	b, _ := strconv.ParseBool(v)
+	return b
+}
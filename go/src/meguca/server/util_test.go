@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strconv"
 	"testing"
 
@@ -110,3 +111,30 @@ func TestText40X(t *testing.T) {
 		})
 	}
 }
+
+func TestFormBool(t *testing.T) {
+	t.Parallel()
+
+	cases := [...]struct {
+		name, val string
+		res       bool
+	}{
+		{"checkbox", "on", true},
+		{"true", "true", true},
+		{"one", "1", true},
+		{"false", "false", false},
+		{"empty", "", false},
+		{"invalid", "foo", false},
+	}
+
+	for i := range cases {
+		c := cases[i]
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			f := url.Values{"sage": {c.val}}
+			if res := formBool(f, "sage"); res != c.res {
+				t.Fatalf("unexpected result: %t : %t", c.res, res)
+			}
+		})
+	}
+}
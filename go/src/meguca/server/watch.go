@@ -0,0 +1,86 @@
+// Thread watching handlers
+
+package server
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"meguca/auth"
+	"meguca/db"
+)
+
+// Parse the thread ID and assert the user is logged in
+func parseWatchRequest(w http.ResponseWriter, r *http.Request) (
+	ss *auth.Session, id uint64, ok bool,
+) {
+	id, err := strconv.ParseUint(getParam(r, "thread"), 10, 64)
+	if err != nil {
+		serveErrorJSON(w, r, aerrNotThread)
+		return
+	}
+	ss = assertSession(w, r, "")
+	ok = ss != nil
+	return
+}
+
+// Start watching a thread. Watching an already watched thread marks it
+// as viewed.
+func watchThread(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(getParam(r, "thread"), 10, 64)
+	if err != nil {
+		serveErrorJSON(w, r, aerrNotThread)
+		return
+	}
+	board, err := db.GetPostBoard(id)
+	switch err {
+	case nil:
+	case sql.ErrNoRows:
+		serveErrorJSON(w, r, aerrNotThread)
+		return
+	default:
+		serveErrorJSON(w, r, aerrInternal.Hide(err))
+		return
+	}
+	ss := assertSession(w, r, board)
+	if ss == nil || !assertNotModOnlyAPI(w, board, ss) {
+		return
+	}
+
+	switch err := db.WatchThread(ss.UserID, id); err {
+	case nil:
+		serveEmptyJSON(w, r)
+	case sql.ErrNoRows:
+		serveErrorJSON(w, r, aerrNotThread)
+	default:
+		serveErrorJSON(w, r, aerrInternal.Hide(err))
+	}
+}
+
+func unwatchThread(w http.ResponseWriter, r *http.Request) {
+	ss, id, ok := parseWatchRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := db.UnwatchThread(ss.UserID, id); err != nil {
+		serveErrorJSON(w, r, aerrInternal.Hide(err))
+		return
+	}
+	serveEmptyJSON(w, r)
+}
+
+// Serve the threads watched by the logged in account with their unread
+// post counts
+func serveWatched(w http.ResponseWriter, r *http.Request) {
+	ss := assertSession(w, r, "")
+	if ss == nil {
+		return
+	}
+	ts, err := db.GetWatchedThreads(ss.UserID)
+	if err != nil {
+		serveErrorJSON(w, r, aerrInternal.Hide(err))
+		return
+	}
+	serveJSON(w, r, ts)
+}
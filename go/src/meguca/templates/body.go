@@ -6,9 +6,12 @@ import (
 	"bytes"
 	"meguca/assets"
 	"meguca/common"
+	"meguca/config"
 	"meguca/db"
 	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	b "github.com/cutechan/blackfriday"
 	"github.com/microcosm-cc/bluemonday"
@@ -68,7 +71,8 @@ var policy = func() *bluemonday.Policy {
 	p.AllowAttrs("data-id").Matching(bluemonday.Integer).OnElements("a")
 	p.AllowAttrs("data-provider").Matching(bluemonday.SpaceSeparatedTokens).OnElements("a")
 	p.AllowAttrs("class").Matching(bluemonday.SpaceSeparatedTokens).OnElements("i")
-	p.AllowAttrs("title").Matching(regexp.MustCompile(`^[-:!%\w]+$`)).OnElements("i")
+	p.AllowAttrs("title").Matching(regexp.MustCompile(`^[-+:!%\w]+$`)).OnElements("i")
+	p.AllowAttrs("data-time").Matching(bluemonday.Integer).OnElements("i")
 	p.AllowAttrs("title").Matching(regexp.MustCompile(`^[-:!%\w ()]+$`)).OnElements("img")
 	return p
 }()
@@ -102,16 +106,142 @@ var LinkEmbeds = func() map[string]*regexp.Regexp {
 }()
 
 var (
-	RollQueryRe = regexp.MustCompile(`^(0|[1-9][0-9]?)-([1-9][0-9]?[0-9]?)$`)
-	FlipQueryRe = regexp.MustCompile(`^([1-9][0-9]?)%$`)
+	RollQueryRe      = regexp.MustCompile(`^(0|[1-9][0-9]?)-([1-9][0-9]?[0-9]?)$`)
+	FlipQueryRe      = regexp.MustCompile(`^([1-9][0-9]?)%$`)
+	DiceQueryRe      = regexp.MustCompile(`^([1-9]|10)d([1-9][0-9]?[0-9]?)([+-][1-9][0-9]?[0-9]?)?$`)
+	CountdownQueryRe = regexp.MustCompile(`^[1-9][0-9]{0,10}$`)
+	PostLinkRe       = regexp.MustCompile(`^>>(?:>/([a-z0-9]{1,10})/)?([0-9]*)$`)
+)
+
+// MatchPostLink splits post link text into the optional board prefix and
+// post ID. ID is 0 for links to the board itself, e.g. >>>/a/.
+func MatchPostLink(text []byte) (board string, id uint64, ok bool) {
+	m := PostLinkRe.FindSubmatch(text)
+	if m == nil {
+		return
+	}
+	board = string(m[1])
+	if len(m[2]) == 0 {
+		return board, 0, board != ""
+	}
+	id, err := strconv.ParseUint(string(m[2]), 10, 64)
+	return board, id, err == nil
+}
+
+// Commands and post links in plain text. The Markdown lexer only recognizes
+// the older command syntax and >>123 links, so the rest is lexed here.
+// MUST BE KEPT IN SYNC with the command and reflink rules of
+// ts/templates/marked.js and ts/templates/body.ts!
+var inlineTokenRe = regexp.MustCompile(
+	`!(roll|flip|8ball|countdown|pick)([\w+%-]*)` +
+		`|>>(?:>/[a-z0-9]{1,10}/[0-9]*|[0-9]+)`)
+
+// InlineRenderer renders the commands and post links found by TextLexer
+type InlineRenderer interface {
+	PostLink(out *bytes.Buffer, text []byte)
+	Command(out *bytes.Buffer, text []byte, c, q string)
+}
+
+// TextLexer finds commands and post links in the plain text passed to the
+// NormalText callback of a renderer.
+//
+// The Markdown lexer passes every '>', that does not start a >>123 link, as
+// a separate chunk of text, so the leading '>' of a >>>/board/123 link arrive
+// in preceding calls. These are taken back from the output and lexed
+// together with the rest of the link.
+type TextLexer struct {
+	out *bytes.Buffer // Output of the last call
+	end int           // Length of out after the last call
+	gts int           // Unmatched '>' at the end of the last call's text
+}
+
+// Lex renders text with normal and passes commands and post links to r
+func (l *TextLexer) Lex(
+	out *bytes.Buffer,
+	text []byte,
+	r InlineRenderer,
+	normal func(out *bytes.Buffer, text []byte),
+) {
+	if l.gts != 0 && l.out == out && out.Len() == l.end {
+		escaped := bytes.Repeat([]byte("&gt;"), l.gts)
+		if bytes.HasSuffix(out.Bytes(), escaped) {
+			out.Truncate(out.Len() - len(escaped))
+			text = append(bytes.Repeat([]byte{'>'}, l.gts), text...)
+		}
+	}
+
+	for {
+		m := inlineTokenRe.FindSubmatchIndex(text)
+		if m == nil {
+			break
+		}
+		normal(out, text[:m[0]])
+		token := text[m[0]:m[1]]
+		if m[2] != -1 {
+			r.Command(out, token, string(text[m[2]:m[3]]),
+				string(text[m[4]:m[5]]))
+		} else {
+			r.PostLink(out, token)
+		}
+		text = text[m[1]:]
+	}
+	normal(out, text)
+
+	gts := 0
+	for gts < 3 && gts < len(text) && text[len(text)-1-gts] == '>' {
+		gts++
+	}
+	l.out, l.end, l.gts = out, out.Len(), gts
+}
+
+// MatchCommand returns the type of command c with query q, if it's valid.
+func MatchCommand(c, q string) (common.CommandType, bool) {
+	switch c {
+	case "roll":
+		if RollQueryRe.MatchString(q) {
+			return common.Roll, true
+		}
+		if DiceQueryRe.MatchString(q) {
+			return common.Dice, true
+		}
+	case "flip":
+		if FlipQueryRe.MatchString(q) {
+			return common.Flip, true
+		}
+	case "8ball":
+		if q == "" {
+			return common.EightBall, true
+		}
+	case "countdown":
+		if CountdownQueryRe.MatchString(q) {
+			return common.Countdown, true
+		}
+	case "pick":
+		if q == "" {
+			return common.Pick, true
+		}
+	}
+	return 0, false
+}
+
+// Escapes command results the same way as escape() in ts/util, so both
+// renderers produce identical markup.
+var commandEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	`"`, "&quot;",
+	"'", "&apos;",
 )
 
 type renderer struct {
 	op       uint64
+	board    string
 	index    bool
 	links    common.Links
 	commands common.Commands
 	cmdi     int
+	lexer    TextLexer
 	*b.Html
 }
 
@@ -147,27 +277,47 @@ func (r *renderer) AutoLink(out *bytes.Buffer, link []byte, kind int) {
 	r.Html.AutoLink(out, link, kind)
 }
 
+func (r *renderer) NormalText(out *bytes.Buffer, text []byte) {
+	r.lexer.Lex(out, text, r, r.Html.NormalText)
+}
+
 func (r *renderer) PostLink(out *bytes.Buffer, text []byte) {
+	board, id, ok := MatchPostLink(text)
+	if !ok {
+		b.AttrEscape(out, text)
+		return
+	}
+	if id == 0 {
+		if !config.IsBoard(board) {
+			b.AttrEscape(out, text)
+			return
+		}
+		out.WriteString(renderBoardLink(board))
+		return
+	}
 	if r.links == nil {
 		b.AttrEscape(out, text)
 		return
 	}
 
-	idStr := string(text[2:])
-	id, _ := strconv.ParseUint(idStr, 10, 64)
-	var op uint64
+	var link common.Link
 	for _, l := range r.links {
-		if l[0] == id {
-			op = l[1]
+		if l.ID == id {
+			link = l
 			break
 		}
 	}
-	if op == 0 {
+	if link.OP == 0 || (board != "" && board != link.Board) {
 		b.AttrEscape(out, text)
 		return
 	}
 
-	out.WriteString(renderPostLink(id, op != r.op, r.index))
+	// Only show board prefix for links leading outside of current board.
+	board = link.Board
+	if board == r.board {
+		board = ""
+	}
+	out.WriteString(renderPostLink(id, board, link.OP != r.op, r.index))
 }
 
 func (r *renderer) Smile(out *bytes.Buffer, text []byte, id string) {
@@ -205,52 +355,90 @@ func (r *renderer) Smile(out *bytes.Buffer, text []byte, id string) {
 }
 
 func (r *renderer) Command(out *bytes.Buffer, text []byte, c, q string) {
-	if r.cmdi >= len(r.commands) {
+	typ, ok := MatchCommand(c, q)
+	if !ok || r.cmdi >= len(r.commands) || r.commands[r.cmdi].Type != typ {
 		b.AttrEscape(out, text)
 		return
 	}
 	cmd := r.commands[r.cmdi]
+	r.cmdi++
 
-	switch c {
-	case "roll":
-		if RollQueryRe.MatchString(q) {
-			out.WriteString("<i class=\"fa fa-cube post-command post-roll-command")
-			out.WriteString("\" title=\"")
-			out.Write(text)
-			out.WriteString("\"> ")
-			out.WriteString(strconv.Itoa(cmd.Roll))
-			out.WriteString(" (")
-			out.WriteString(q)
-			out.WriteString(")</i>")
-			r.cmdi++
-			return
+	switch cmd.Type {
+	case common.Roll:
+		out.WriteString("<i class=\"fa fa-cube post-command post-roll-command")
+		out.WriteString("\" title=\"")
+		out.Write(text)
+		out.WriteString("\"> ")
+		out.WriteString(strconv.Itoa(cmd.Roll))
+		out.WriteString(" (")
+		out.WriteString(q)
+		out.WriteString(")</i>")
+	case common.Flip:
+		out.WriteString("<i class=\"fa fa-cube post-command post-flip-command ")
+		if cmd.Flip {
+			out.WriteString("post-flip-command_hit")
+		} else {
+			out.WriteString("post-flip-command_miss")
 		}
-	case "flip":
-		if FlipQueryRe.MatchString(q) {
-			out.WriteString("<i class=\"fa fa-cube post-command post-flip-command ")
-			if cmd.Flip {
-				out.WriteString("post-flip-command_hit")
-			} else {
-				out.WriteString("post-flip-command_miss")
-			}
-			out.WriteString("\" title=\"")
-			out.Write(text)
-			out.WriteString("\"> ")
-			out.WriteString(q)
-			out.WriteString("</i>")
-			r.cmdi++
-			return
+		out.WriteString("\" title=\"")
+		out.Write(text)
+		out.WriteString("\"> ")
+		out.WriteString(q)
+		out.WriteString("</i>")
+	case common.Dice:
+		rolls := make([]string, len(cmd.Dice))
+		total := 0
+		for i, v := range cmd.Dice {
+			rolls[i] = strconv.Itoa(v)
+			total += v
 		}
+		if m := DiceQueryRe.FindStringSubmatch(q); m[3] != "" {
+			mod, _ := strconv.Atoi(m[3])
+			total += mod
+		}
+		out.WriteString("<i class=\"fa fa-cube post-command post-dice-command")
+		out.WriteString("\" title=\"")
+		out.Write(text)
+		out.WriteString("\"> ")
+		out.WriteString(strconv.Itoa(total))
+		out.WriteString(" (")
+		out.WriteString(q)
+		out.WriteString(": ")
+		out.WriteString(strings.Join(rolls, ", "))
+		out.WriteString(")</i>")
+	case common.EightBall:
+		out.WriteString("<i class=\"fa fa-question-circle post-command post-8ball-command")
+		out.WriteString("\" title=\"")
+		out.Write(text)
+		out.WriteString("\"> ")
+		commandEscaper.WriteString(out, cmd.Text)
+		out.WriteString("</i>")
+	case common.Countdown:
+		out.WriteString("<i class=\"fa fa-clock-o post-command post-countdown-command")
+		out.WriteString("\" title=\"")
+		out.Write(text)
+		out.WriteString("\" data-time=\"")
+		out.WriteString(q)
+		out.WriteString("\"> ")
+		t := time.Unix(cmd.Countdown, 0).UTC()
+		out.WriteString(t.Format("2006-01-02 15:04 UTC"))
+		out.WriteString("</i>")
+	case common.Pick:
+		out.WriteString("<i class=\"fa fa-list post-command post-pick-command")
+		out.WriteString("\" title=\"")
+		out.Write(text)
+		out.WriteString("\"> ")
+		commandEscaper.WriteString(out, cmd.Text)
+		out.WriteString("</i>")
 	}
-
-	b.AttrEscape(out, text)
 }
 
 // Render post body Markdown to sanitized HTML.
-func renderBody(p *common.Post, op uint64, index bool) string {
+func renderBody(p *common.Post, op uint64, board string, index bool) string {
 	input := []byte(p.Body)
 	renderer := &renderer{
 		op:       op,
+		board:    board,
 		index:    index,
 		links:    p.Links,
 		commands: p.Commands,
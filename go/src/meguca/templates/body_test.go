@@ -1,9 +1,11 @@
 package templates
 
 import (
+	"bytes"
 	"meguca/common"
 	"meguca/config"
 	. "meguca/test"
+	"strings"
 	"testing"
 )
 
@@ -16,7 +18,7 @@ func TestRenderBody(t *testing.T) {
 		name, in, out string
 		editing       bool
 		op            uint64
-		links         [][2]uint64
+		links         common.Links
 		commands      []common.Command
 	}{
 		{
@@ -152,28 +154,54 @@ func TestRenderBody(t *testing.T) {
 			name:  "1 invalid link",
 			in:    ">>20",
 			out:   "<em>&gt;&gt;20</em>",
-			links: [][2]uint64{{21, 21}},
+			links: common.Links{{ID: 21, OP: 21, Board: "a"}},
 		},
 		{
 			name:  "valid link",
 			in:    ">>21",
 			out:   `<em><a class="post-link" data-id="21" href="#21">&gt;&gt;21</a></em>`,
 			op:    20,
-			links: [][2]uint64{{21, 20}},
+			links: common.Links{{ID: 21, OP: 20, Board: "a"}},
 		},
 		{
 			name:  "valid link with extra quotes",
 			in:    ">>>>21",
 			out:   `<em>&gt;&gt;<a class="post-link" data-id="21" href="#21">&gt;&gt;21</a></em>`,
 			op:    20,
-			links: [][2]uint64{{21, 20}},
+			links: common.Links{{ID: 21, OP: 20, Board: "a"}},
 		},
 		{
 			name:  "valid cross-thread link",
 			in:    ">>21",
 			out:   `<em><a class="post-link" data-id="21" href="/all/21#21">&gt;&gt;21 ➡</a></em>`,
 			op:    20,
-			links: [][2]uint64{{21, 22}},
+			links: common.Links{{ID: 21, OP: 22, Board: "a"}},
+		},
+		{
+			name:  "valid cross-board link",
+			in:    ">>>/b/21",
+			out:   `<em><a class="post-link" data-id="21" href="/all/21#21">&gt;&gt;&gt;/b/21 ➡</a></em>`,
+			op:    20,
+			links: common.Links{{ID: 21, OP: 22, Board: "b"}},
+		},
+		{
+			name:  "board-qualified link to current board",
+			in:    ">>>/a/21",
+			out:   `<em><a class="post-link" data-id="21" href="#21">&gt;&gt;21</a></em>`,
+			op:    20,
+			links: common.Links{{ID: 21, OP: 20, Board: "a"}},
+		},
+		{
+			name:  "link to post on other board",
+			in:    ">>>/c/21",
+			out:   "<em>&gt;&gt;&gt;/c/21</em>",
+			op:    20,
+			links: common.Links{{ID: 21, OP: 20, Board: "a"}},
+		},
+		{
+			name: "board link",
+			in:   ">>>/a/",
+			out:  `<em><a class="board-link" href="/a/">&gt;&gt;&gt;/a/</a></em>`,
 		},
 		{
 			name: "invalid reference",
@@ -233,7 +261,7 @@ func TestRenderBody(t *testing.T) {
 				Links:    c.links,
 				Commands: c.commands,
 			}
-			s := renderBody(p, c.op, false)
+			s := renderBody(p, c.op, "a", false)
 
 			if s != c.out {
 				LogUnexpected(t, c.out, s)
@@ -241,3 +269,229 @@ func TestRenderBody(t *testing.T) {
 		})
 	}
 }
+
+// Expected markup is what renderCommand in ts/templates/body.ts produces
+// for the same input.
+func TestRenderCommand(t *testing.T) {
+	cases := [...]struct {
+		name, text, c, q, out string
+		commands              common.Commands
+	}{
+		{
+			name:     "roll",
+			text:     "!roll1-100",
+			c:        "roll",
+			q:        "1-100",
+			commands: common.Commands{{Type: common.Roll, Roll: 42}},
+			out:      `<i class="fa fa-cube post-command post-roll-command" title="!roll1-100"> 42 (1-100)</i>`,
+		},
+		{
+			name:     "flip hit",
+			text:     "!flip50%",
+			c:        "flip",
+			q:        "50%",
+			commands: common.Commands{{Type: common.Flip, Flip: true}},
+			out:      `<i class="fa fa-cube post-command post-flip-command post-flip-command_hit" title="!flip50%"> 50%</i>`,
+		},
+		{
+			name:     "flip miss",
+			text:     "!flip50%",
+			c:        "flip",
+			q:        "50%",
+			commands: common.Commands{{Type: common.Flip}},
+			out:      `<i class="fa fa-cube post-command post-flip-command post-flip-command_miss" title="!flip50%"> 50%</i>`,
+		},
+		{
+			name:     "dice",
+			text:     "!roll3d6",
+			c:        "roll",
+			q:        "3d6",
+			commands: common.Commands{{Type: common.Dice, Dice: []int{1, 2, 3}}},
+			out:      `<i class="fa fa-cube post-command post-dice-command" title="!roll3d6"> 6 (3d6: 1, 2, 3)</i>`,
+		},
+		{
+			name:     "dice with bonus",
+			text:     "!roll3d6+2",
+			c:        "roll",
+			q:        "3d6+2",
+			commands: common.Commands{{Type: common.Dice, Dice: []int{1, 2, 3}}},
+			out:      `<i class="fa fa-cube post-command post-dice-command" title="!roll3d6+2"> 8 (3d6+2: 1, 2, 3)</i>`,
+		},
+		{
+			name:     "dice with penalty",
+			text:     "!roll2d10-1",
+			c:        "roll",
+			q:        "2d10-1",
+			commands: common.Commands{{Type: common.Dice, Dice: []int{4, 5}}},
+			out:      `<i class="fa fa-cube post-command post-dice-command" title="!roll2d10-1"> 8 (2d10-1: 4, 5)</i>`,
+		},
+		{
+			name:     "8ball",
+			text:     "!8ball",
+			c:        "8ball",
+			commands: common.Commands{{Type: common.EightBall, Text: "Don't count on it"}},
+			out:      `<i class="fa fa-question-circle post-command post-8ball-command" title="!8ball"> Don&apos;t count on it</i>`,
+		},
+		{
+			name:     "countdown",
+			text:     "!countdown1700000000",
+			c:        "countdown",
+			q:        "1700000000",
+			commands: common.Commands{{Type: common.Countdown, Countdown: 1700000000}},
+			out:      `<i class="fa fa-clock-o post-command post-countdown-command" title="!countdown1700000000" data-time="1700000000"> 2023-11-14 22:13 UTC</i>`,
+		},
+		{
+			name:     "pick",
+			text:     "!pick",
+			c:        "pick",
+			commands: common.Commands{{Type: common.Pick, Text: "<b>tea & cake</b>"}},
+			out:      `<i class="fa fa-list post-command post-pick-command" title="!pick"> &lt;b&gt;tea &amp; cake&lt;/b&gt;</i>`,
+		},
+		{
+			name:     "invalid query",
+			text:     "!roll0-1000",
+			c:        "roll",
+			q:        "0-1000",
+			commands: common.Commands{{Type: common.Roll, Roll: 1}},
+			out:      "!roll0-1000",
+		},
+		{
+			name:     "query on 8ball",
+			text:     "!8ballx",
+			c:        "8ball",
+			q:        "x",
+			commands: common.Commands{{Type: common.EightBall, Text: "Yes"}},
+			out:      "!8ballx",
+		},
+		{
+			name:     "type mismatch",
+			text:     "!flip50%",
+			c:        "flip",
+			q:        "50%",
+			commands: common.Commands{{Type: common.Roll, Roll: 1}},
+			out:      "!flip50%",
+		},
+		{
+			name: "no commands",
+			text: "!pick",
+			c:    "pick",
+			out:  "!pick",
+		},
+	}
+
+	for i := range cases {
+		c := cases[i]
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+			r := &renderer{commands: c.commands}
+			r.Command(&out, []byte(c.text), c.c, c.q)
+			if s := out.String(); s != c.out {
+				LogUnexpected(t, c.out, s)
+			}
+		})
+	}
+}
+
+// Records lexed tokens instead of rendering them
+type tokenRecorder struct{}
+
+func (tokenRecorder) PostLink(out *bytes.Buffer, text []byte) {
+	out.WriteString("{")
+	out.Write(text)
+	out.WriteString("}")
+}
+
+func (tokenRecorder) Command(out *bytes.Buffer, text []byte, c, q string) {
+	out.WriteString("[" + c + ":" + q + "]")
+}
+
+func TestTextLexer(t *testing.T) {
+	t.Parallel()
+
+	escape := func(out *bytes.Buffer, text []byte) {
+		out.WriteString(strings.Replace(string(text), ">", "&gt;", -1))
+	}
+
+	// Chunks of text as passed to NormalText. Empty chunks stand for output
+	// of other callbacks in between.
+	cases := [...]struct {
+		name   string
+		chunks []string
+		out    string
+	}{
+		{
+			name:   "plain text",
+			chunks: []string{"foo bar"},
+			out:    "foo bar",
+		},
+		{
+			name:   "commands",
+			chunks: []string{"a !8ball b !roll3d6+2 !countdown1700000000 !pick"},
+			out:    "a [8ball:] b [roll:3d6+2] [countdown:1700000000] [pick:]",
+		},
+		{
+			name:   "command split by the Markdown lexer",
+			chunks: []string{"foo ", "!roll2d10-1 bar"},
+			out:    "foo [roll:2d10-1] bar",
+		},
+		{
+			name:   "invalid command query is passed on",
+			chunks: []string{"!8ballx"},
+			out:    "[8ball:x]",
+		},
+		{
+			name:   "unknown command",
+			chunks: []string{"!foo"},
+			out:    "!foo",
+		},
+		{
+			name:   "cross-board link",
+			chunks: []string{">>>/a/1 foo"},
+			out:    "{>>>/a/1} foo",
+		},
+		{
+			name:   "cross-board link split by the Markdown lexer",
+			chunks: []string{"foo ", ">", ">", ">/a/1 bar"},
+			out:    "foo {>>>/a/1} bar",
+		},
+		{
+			name:   "board link split by the Markdown lexer",
+			chunks: []string{">", ">", ">/a/"},
+			out:    "{>>>/a/}",
+		},
+		{
+			name:   "quote characters",
+			chunks: []string{"a >", ">", " b"},
+			out:    "a &gt;&gt; b",
+		},
+		{
+			name:   "other output in between",
+			chunks: []string{">", "", ">", ">/a/1"},
+			out:    "&gt;<x>&gt;&gt;/a/1",
+		},
+	}
+
+	for i := range cases {
+		c := cases[i]
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				out bytes.Buffer
+				l   TextLexer
+			)
+			for _, chunk := range c.chunks {
+				if chunk == "" {
+					out.WriteString("<x>")
+					continue
+				}
+				l.Lex(&out, []byte(chunk), tokenRecorder{}, escape)
+			}
+			if s := out.String(); s != c.out {
+				LogUnexpected(t, c.out, s)
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+// Atom and RSS feeds of boards and threads
+
+package templates
+
+import (
+	"encoding/xml"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"meguca/assets"
+	"meguca/common"
+)
+
+// Maximum number of entries in a feed
+const feedLength = 50
+
+type feedItem struct {
+	title   string
+	link    string
+	time    int64
+	author  string
+	content string
+	files   []*common.Image
+}
+
+type feed struct {
+	root     string
+	title    string
+	link     string
+	selfLink string
+	items    []feedItem
+}
+
+// BoardFeed renders the newest threads of a board. root is the absolute
+// URL of the site without the trailing slash.
+func BoardFeed(root, board, title string, threads common.Board, atom bool) ([]byte, error) {
+	threads = append(common.Board(nil), threads...)
+	sort.Slice(threads, func(i, j int) bool {
+		return threads[i].ID > threads[j].ID
+	})
+	if len(threads) > feedLength {
+		threads = threads[:feedLength]
+	}
+
+	f := feed{
+		root:     root,
+		title:    title,
+		link:     root + "/" + board + "/",
+		selfLink: root + "/" + board + "/" + feedFile(atom),
+		items:    make([]feedItem, 0, len(threads)),
+	}
+	for _, t := range threads {
+		f.items = append(f.items, makeFeedItem(root, t, t.Post, t.Subject))
+	}
+	return f.encode(atom)
+}
+
+// ThreadFeed renders the newest replies to a thread
+func ThreadFeed(root string, t common.Thread, atom bool) ([]byte, error) {
+	idStr := strconv.FormatUint(t.ID, 10)
+	threadURL := root + "/" + t.Board + "/" + idStr
+	f := feed{
+		root:     root,
+		title:    t.Subject,
+		link:     threadURL,
+		selfLink: threadURL + "/" + feedFile(atom),
+	}
+
+	posts := t.Posts
+	if len(posts) > feedLength {
+		posts = posts[len(posts)-feedLength:]
+	}
+	f.items = make([]feedItem, 0, len(posts))
+	for i := len(posts) - 1; i >= 0; i-- {
+		p := posts[i]
+		title := "#" + strconv.FormatUint(p.ID, 10)
+		f.items = append(f.items, makeFeedItem(root, t, p, title))
+	}
+	return f.encode(atom)
+}
+
+func feedFile(atom bool) string {
+	if atom {
+		return "feed.atom"
+	}
+	return "feed.rss"
+}
+
+func makeFeedItem(root string, t common.Thread, p *common.Post, title string) feedItem {
+	link := root + "/" + t.Board + "/" + strconv.FormatUint(t.ID, 10)
+	if p.ID != t.ID {
+		link += "#" + strconv.FormatUint(p.ID, 10)
+	}
+	return feedItem{
+		title:   title,
+		link:    link,
+		time:    p.Time,
+		author:  p.UserName,
+		content: renderBody(p, t.ID, t.Board, true),
+		files:   p.Files,
+	}
+}
+
+// Absolute URL and MIME type of a file thumbnail
+func feedThumb(root string, img *common.Image) (url, typ string) {
+	url = assets.ThumbPath(img.ThumbType, img.SHA1)
+	if strings.HasPrefix(url, "/") {
+		url = root + url
+	}
+	typ = mime.TypeByExtension("." + common.Extensions[img.ThumbType])
+	if typ == "" {
+		typ = "application/octet-stream"
+	}
+	return
+}
+
+func (f feed) updated() int64 {
+	var t int64
+	for _, it := range f.items {
+		if it.time > t {
+			t = it.time
+		}
+	}
+	return t
+}
+
+func (f feed) encode(atom bool) ([]byte, error) {
+	var v interface{}
+	if atom {
+		v = f.atom()
+	} else {
+		v = f.rss()
+	}
+	buf, err := xml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), buf...), nil
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Author  atomAuthor  `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Author  *atomAuthor `xml:"author,omitempty"`
+	Links   []atomLink  `xml:"link"`
+	Content atomContent `xml:"content"`
+}
+
+func (f feed) atom() atomFeed {
+	a := atomFeed{
+		Title:   f.title,
+		ID:      f.link,
+		Updated: time.Unix(f.updated(), 0).UTC().Format(time.RFC3339),
+		Author:  atomAuthor{Name: f.title},
+		Links: []atomLink{
+			{Href: f.link},
+			{Rel: "self", Type: "application/atom+xml", Href: f.selfLink},
+		},
+		Entries: make([]atomEntry, 0, len(f.items)),
+	}
+	for _, it := range f.items {
+		e := atomEntry{
+			Title:   it.title,
+			ID:      it.link,
+			Updated: time.Unix(it.time, 0).UTC().Format(time.RFC3339),
+			Links:   []atomLink{{Href: it.link}},
+			Content: atomContent{Type: "html", Body: it.content},
+		}
+		if it.author != "" {
+			e.Author = &atomAuthor{Name: it.author}
+		}
+		for _, img := range it.files {
+			url, typ := feedThumb(f.root, img)
+			e.Links = append(e.Links, atomLink{
+				Rel:  "enclosure",
+				Type: typ,
+				Href: url,
+			})
+		}
+		a.Entries = append(a.Entries, e)
+	}
+	return a
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	GUID        string        `xml:"guid"`
+	PubDate     string        `xml:"pubDate"`
+	Description string        `xml:"description"`
+	Enclosure   *rssEnclosure `xml:"enclosure,omitempty"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length string `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+func (f feed) rss() rssFeed {
+	c := rssChannel{
+		Title:         f.title,
+		Link:          f.link,
+		Description:   f.title,
+		LastBuildDate: time.Unix(f.updated(), 0).UTC().Format(time.RFC1123Z),
+		Items:         make([]rssItem, 0, len(f.items)),
+	}
+	for _, it := range f.items {
+		item := rssItem{
+			Title:       it.title,
+			Link:        it.link,
+			GUID:        it.link,
+			PubDate:     time.Unix(it.time, 0).UTC().Format(time.RFC1123Z),
+			Description: it.content,
+		}
+		// RSS allows only a single enclosure per item.
+		if len(it.files) != 0 {
+			url, typ := feedThumb(f.root, it.files[0])
+			item.Enclosure = &rssEnclosure{URL: url, Length: "0", Type: typ}
+		}
+		c.Items = append(c.Items, item)
+	}
+	return rssFeed{Version: "2.0", Channel: c}
+}
@@ -29,7 +29,10 @@ type PostContext struct {
 	Subject   string
 	Badge     bool
 	Auth      string
+	Sage      bool
+	LSage     string
 	Name      string
+	Trip      string
 	Color     string
 	ShowColor bool
 	Time      string
@@ -64,6 +67,7 @@ type FileContext struct {
 type PostLinkContext struct {
 	ID    string
 	URL   string
+	Board string
 	Cross bool
 }
 
@@ -92,7 +96,10 @@ func MakePostContext(l string, t common.Thread, p *common.Post, bls common.Backl
 		Subject:   t.Subject,
 		Badge:     p.Auth != "",
 		Auth:      lang.Get(l, p.Auth),
+		Sage:      p.Sage,
+		LSage:     lang.Get(l, "sage"),
 		Name:      p.UserName,
+		Trip:      p.Trip,
 		Color:     p.UserColor,
 		ShowColor: p.ShowColor,
 		Reacts:    p.Reacts,
@@ -239,11 +246,12 @@ func (ctx *PostContext) getDownloadName(n int, img *common.Image) string {
 }
 
 func (ctx *PostContext) Body() string {
-	return renderBody(ctx.post, ctx.TID, ctx.Index)
+	return renderBody(ctx.post, ctx.TID, ctx.Board, ctx.Index)
 }
 
-// Render a link to another post. Can optionally be cross-thread.
-func renderPostLink(id uint64, cross, index bool) string {
+// Render a link to another post. Can optionally be cross-thread and
+// prefixed with the board of the post.
+func renderPostLink(id uint64, board string, cross, index bool) string {
 	idStr := strconv.FormatUint(id, 10)
 	url := ""
 	if cross || index {
@@ -253,11 +261,17 @@ func renderPostLink(id uint64, cross, index bool) string {
 	linkCtx := PostLinkContext{
 		ID:    idStr,
 		URL:   url,
+		Board: board,
 		Cross: cross,
 	}
 	return renderMustache("post-link", &linkCtx)
 }
 
+// Render a link to the board index.
+func renderBoardLink(board string) string {
+	return `<a class="board-link" href="/` + board + `/">&gt;&gt;&gt;/` + board + `/</a>`
+}
+
 func (ctx *PostContext) Backlinks() string {
 	links := ctx.backlinks[ctx.ID]
 	if links == nil {
@@ -276,7 +290,7 @@ func (ctx *PostContext) Backlinks() string {
 	rendered := make([]string, len(links))
 	for i, id := range ids {
 		op := links[id]
-		rendered[i] = renderPostLink(id, op != ctx.TID, ctx.Index)
+		rendered[i] = renderPostLink(id, "", op != ctx.TID, ctx.Index)
 	}
 
 	linkCtx := BacklinksContext{
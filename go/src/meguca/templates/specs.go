@@ -113,6 +113,11 @@ var specs = map[string][]inputSpec{
 			ID:   "imageRootOverride",
 			Type: _string,
 		},
+		{
+			ID:   "modLogRetention",
+			Type: _number,
+			Min:  0,
+		},
 	},
 }
 
@@ -7,9 +7,11 @@ package templates
 import (
 	"bytes"
 	"fmt"
+	"net/url"
 	"strings"
 
 	"meguca/auth"
+	"meguca/common"
 	"meguca/config"
 	"meguca/lang"
 
@@ -45,11 +47,11 @@ func Board(
 func Thread(
 	id uint64,
 	l, board, title string,
-	abbrev bool,
+	abbrev, closed bool,
 	ss *auth.Session,
 	postHTML []byte,
 ) []byte {
-	html := renderThread(postHTML, id, l, board, title)
+	html := renderThread(postHTML, id, l, board, title, closed)
 	return Page(ss, l, title, html, true)
 }
 
@@ -65,6 +67,30 @@ func Stickers(ss *auth.Session, l string, stickHTML []byte) []byte {
 	return Page(ss, l, title, html, false)
 }
 
+func Search(
+	ss *auth.Session,
+	l string,
+	q url.Values,
+	posts []common.SearchResult,
+	page int,
+	more bool,
+) []byte {
+	html := renderSearch(l, q, posts, page, more)
+	title := lang.Get(l, "search")
+	return Page(ss, l, title, html, false)
+}
+
+func Banned(
+	ss *auth.Session,
+	l string,
+	ban auth.BanRecord,
+	appeal *auth.BanAppeal,
+) []byte {
+	html := renderBanned(l, ban, appeal)
+	title := lang.Get(l, "banned")
+	return Page(ss, l, title, html, false)
+}
+
 func Admin(
 	ss *auth.Session,
 	l string,
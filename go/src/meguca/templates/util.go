@@ -2,6 +2,8 @@ package templates
 
 import (
 	"html"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -33,10 +35,10 @@ func extractBacklinks(cap int, threads ...common.Thread) common.Backlinks {
 	bls := make(common.Backlinks, cap)
 	register := func(p *common.Post, op uint64) {
 		for _, l := range p.Links {
-			m, ok := bls[l[0]]
+			m, ok := bls[l.ID]
 			if !ok {
 				m = make(map[uint64]uint64, 4)
-				bls[l[0]] = m
+				bls[l.ID] = m
 			}
 			m[p.ID] = op
 		}
@@ -109,3 +111,13 @@ type sortableUInt64 []uint64
 func (a sortableUInt64) Len() int           { return len(a) }
 func (a sortableUInt64) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a sortableUInt64) Less(i, j int) bool { return a[i] < a[j] }
+
+// Link to a different page of the same search results
+func searchPageURL(q url.Values, page int) string {
+	v := make(url.Values, len(q))
+	for k, vals := range q {
+		v[k] = vals
+	}
+	v.Set("page", strconv.Itoa(page))
+	return "/search/?" + v.Encode()
+}
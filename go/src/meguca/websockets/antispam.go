@@ -0,0 +1,93 @@
+// Spam detection on post creation
+
+package websockets
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"time"
+	"unicode/utf8"
+
+	"meguca/auth"
+	"meguca/config"
+	"meguca/db"
+)
+
+var (
+	// Returned on post creation, when the post must carry a solved captcha
+	ErrCaptchaRequired = errors.New("captcha required")
+	errInvalidCaptcha  = errors.New("invalid captcha")
+	errTooManyLinks    = errors.New("too many links in post")
+
+	externalLinkRe = regexp.MustCompile(`(?i)\bhttps?://`)
+)
+
+// Score the post and reject it, if its author is likely spamming. Solving
+// a captcha resets the author's score.
+func checkSpam(tx *sql.Tx, req PostCreationRequest, post db.Post) (
+	err error,
+) {
+	conf := config.Get().Antispam
+	if conf.CaptchaScore <= 0 {
+		return
+	}
+	if req.Captcha.CaptchaID != "" {
+		if !auth.AuthenticateCaptcha(req.Captcha) {
+			return errInvalidCaptcha
+		}
+		auth.ResetSpamScore(req.Ip)
+	} else if !auth.CanPost(req.Ip) {
+		return ErrCaptchaRequired
+	}
+
+	now := time.Now()
+	since := now.Add(-time.Duration(conf.Window) * time.Minute)
+	score := auth.PostCreationScore
+
+	// New IPs are limited in the number of outbound links
+	links := len(externalLinkRe.FindAllStringIndex(post.Body, -1))
+	if links != 0 {
+		period := time.Duration(conf.NewIPPeriod) * time.Hour
+		var isNew bool
+		isNew, err = db.IsNewIP(tx, req.Ip, now.Add(-period))
+		if err != nil {
+			return
+		}
+		if isNew && links > conf.MaxNewIPLinks {
+			return errTooManyLinks
+		}
+		score += time.Duration(links) * auth.LinkScore
+	}
+
+	// Same text posted across threads
+	if utf8.RuneCountInString(post.Body) >= conf.MinDuplicateLen {
+		var n int
+		n, err = db.CountDuplicateBodies(tx, post.Body, post.OP, since)
+		if err != nil {
+			return
+		}
+		score += time.Duration(n*conf.DuplicateScore) * time.Second
+	}
+
+	// Same files posted repeatedly
+	if len(post.Files) != 0 {
+		sha1s := make([]string, len(post.Files))
+		for i, f := range post.Files {
+			sha1s[i] = f.SHA1
+		}
+		var n int
+		n, err = db.CountRecentFiles(tx, sha1s, since)
+		if err != nil {
+			return
+		}
+		score += time.Duration(len(post.Files)) * auth.ImageScore
+		score += time.Duration(n*conf.FileScore) * time.Second
+	}
+
+	exceeded, err := auth.IncrementSpamScore(req.Ip, score)
+	if err == nil && exceeded {
+		err = ErrCaptchaRequired
+	}
+	return
+}
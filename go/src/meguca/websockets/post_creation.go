@@ -16,6 +16,7 @@ import (
 
 	"meguca/auth"
 	"meguca/common"
+	"meguca/config"
 	"meguca/db"
 	"meguca/parser"
 )
@@ -26,12 +27,17 @@ var (
 	errInvalidImageToken = errors.New("invalid image token")
 	errNoTextOrFiles     = errors.New("no text or files")
 	errTooManyLines      = errors.New("too many lines in post body")
+	errPasswordTooLong   = common.ErrTooLong("Password")
+	errThreadClosed      = errors.New("thread is closed")
+	errFilteredPhrase    = errors.New("post contains a filtered phrase")
 )
 
 // ThreadCreationRequest contains data for creating a new thread.
 type ThreadCreationRequest struct {
 	PostCreationRequest
 	Subject string
+	// Optional poll attached to the thread
+	Poll *common.Poll
 }
 
 // PostCreationRequest contains common fields for both thread and post
@@ -46,7 +52,14 @@ type PostCreationRequest struct {
 	Sign         string
 	ShowBadge    bool
 	ShowName     bool
-	Session      *auth.Session
+	Sage         bool
+	// Optional password for deleting the post later
+	Password string
+	// Name field in "name#password" form for generating a tripcode
+	Name    string
+	Session *auth.Session
+	// Optional captcha solution, when spam detection requires one
+	Captcha auth.Captcha
 }
 
 type FilesRequest struct {
@@ -78,6 +91,11 @@ func CreateThread(req ThreadCreationRequest) (
 	if err != nil {
 		return
 	}
+	// Threads can't be hidden until approved
+	if post.HoldReason != "" {
+		err = errFilteredPhrase
+		return
+	}
 
 	post.ID, err = db.NewPostID(tx)
 	if err != nil {
@@ -85,10 +103,21 @@ func CreateThread(req ThreadCreationRequest) (
 	}
 	post.OP = post.ID
 
+	err = checkSpam(tx, req.PostCreationRequest, post)
+	if err != nil {
+		return
+	}
+
 	err = db.InsertThread(tx, post, subject)
 	if err != nil {
 		return
 	}
+	if req.Poll != nil {
+		err = db.InsertPoll(tx, post.ID, *req.Poll)
+		if err != nil {
+			return
+		}
+	}
 
 	err = tx.Commit()
 	return
@@ -110,6 +139,15 @@ func CreatePost(req PostCreationRequest, op uint64) (
 	}
 	defer db.RollbackOnError(tx, &err)
 
+	state, err := db.GetThreadState(tx, op)
+	if err != nil {
+		return
+	}
+	if state.Closed() {
+		err = errThreadClosed
+		return
+	}
+
 	post, err = constructPost(tx, req)
 	if err != nil {
 		return
@@ -121,6 +159,15 @@ func CreatePost(req PostCreationRequest, op uint64) (
 	}
 	post.OP = op
 
+	err = checkSpam(tx, req, post)
+	if err != nil {
+		return
+	}
+
+	// Replies past the bump limit are kept from bumping the thread by
+	// bump_thread(), so only explicit sage is recorded.
+	post.Sage = req.Sage
+
 	msg, err = common.EncodeMessage(common.MessageInsertPost, post.Post)
 	if err != nil {
 		return
@@ -129,6 +176,12 @@ func CreatePost(req PostCreationRequest, op uint64) (
 	if err != nil {
 		return
 	}
+	if post.HoldReason != "" {
+		err = db.HoldPost(tx, post.ID, post.Board, post.HoldReason)
+		if err != nil {
+			return
+		}
+	}
 
 	err = tx.Commit()
 	return
@@ -141,6 +194,15 @@ func constructPost(tx *sql.Tx, req PostCreationRequest) (post db.Post, err error
 		return
 	}
 
+	// Apply word filters before any length checks, as replacements can
+	// make the body longer.
+	var hit *config.WordFilter
+	req.Body, hit = config.FilterWords(req.Board, req.Body)
+	if hit != nil && hit.Mode == config.FilterReject {
+		err = errFilteredPhrase
+		return
+	}
+
 	post = db.Post{
 		StandalonePost: common.StandalonePost{
 			Post: common.Post{
@@ -151,6 +213,10 @@ func constructPost(tx *sql.Tx, req PostCreationRequest) (post db.Post, err error
 		},
 		IP:       req.Ip,
 		UniqueID: req.UniqueID,
+		Token:    req.Token,
+	}
+	if hit != nil {
+		post.HoldReason = hit.HoldReason()
 	}
 
 	// Check token and its signature.
@@ -173,6 +239,25 @@ func constructPost(tx *sql.Tx, req PostCreationRequest) (post db.Post, err error
 		return
 	}
 
+	if len(req.Name) > common.MaxLenName {
+		err = common.ErrNameTooLong
+		return
+	}
+	if !config.GetBoardConfig(req.Board).DisableTrips {
+		post.Trip = auth.Tripcode(req.Name)
+	}
+
+	if req.Password != "" {
+		if len(req.Password) > common.MaxLenPassword {
+			err = errPasswordTooLong
+			return
+		}
+		post.Password, err = auth.BcryptHash(req.Password, 10)
+		if err != nil {
+			return
+		}
+	}
+
 	ss := req.Session
 	if ss != nil {
 		// Attach staff badge if requested after validation.
@@ -189,7 +274,7 @@ func constructPost(tx *sql.Tx, req PostCreationRequest) (post db.Post, err error
 		}
 	}
 
-	post.Links, post.Commands, err = parser.ParseBody([]byte(req.Body))
+	post.Links, post.Commands, err = parser.ParseBody([]byte(req.Body), req.Board)
 	post.Reacts = make(common.Reacts, 0, 64)
 
 	if err != nil {
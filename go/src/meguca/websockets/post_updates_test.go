@@ -290,7 +290,7 @@ func TestClosePostWithLinks(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	AssertDeepEquals(t, post.Links, [][2]uint64{{22, 21}})
+	AssertDeepEquals(t, post.Links, common.Links{{ID: 22, OP: 21, Board: "c"}})
 }
 
 func TestBackspace(t *testing.T) {
@@ -8,6 +8,7 @@ import (
 	"log"
 	"meguca/auth"
 	"meguca/common"
+	"meguca/db"
 	"meguca/feeds"
 	"meguca/util"
 	"net/http"
@@ -58,16 +59,24 @@ type Client struct {
 	conn *websocket.Conn
 	// Client IP
 	ip string
+	// ID of the logged in account, if any
+	userID string
 	// Internal message receiver channel
 	receive chan receivedMessage
 	// Only used to pass messages from the Send method.
 	sendExternal chan []byte
-	// Redirect client to target board
-	redirect chan string
+	// Redirect client to target board or thread
+	redirect chan redirection
 	// Close the client and free all used resources
 	close chan error
 }
 
+// Board or thread to redirect a client to
+type redirection struct {
+	board string
+	id    uint64 // Zero for board pages
+}
+
 type receivedMessage struct {
 	typ int
 	msg []byte
@@ -104,9 +113,10 @@ func newClient(conn *websocket.Conn, req *http.Request) (*Client, error) {
 	}
 	return &Client{
 		ip:       ip,
+		userID:   getUserID(req),
 		close:    make(chan error, 2),
 		receive:  make(chan receivedMessage),
-		redirect: make(chan string),
+		redirect: make(chan redirection),
 		// Allows for ~60 seconds of messages, until the buffer overflows.
 		// A larger gap is more acceptable to shitty connections and mobile
 		// phones, especially while uploading.
@@ -151,12 +161,11 @@ func (c *Client) listenerLoop() error {
 			if err := c.handleMessage(msg.typ, msg.msg); err != nil {
 				return err
 			}
-		case board := <-c.redirect:
-			err := c.sendMessage(common.MessageRedirect, board)
-			if err != nil {
+		case r := <-c.redirect:
+			if err := c.sendRedirect(r); err != nil {
 				return err
 			}
-			if err := c.registerSync(0, board); err != nil {
+			if err := c.registerSync(r.id, r.board); err != nil {
 				return err
 			}
 		}
@@ -333,11 +342,31 @@ func (c *Client) Close(err error) {
 	}
 }
 
+// Notify the client about a redirect to another board or thread
+func (c *Client) sendRedirect(r redirection) error {
+	if r.id == 0 {
+		return c.sendMessage(common.MessageRedirect, r.board)
+	}
+	return c.sendMessage(common.MessageRedirectThread, struct {
+		Board string `json:"board"`
+		ID    uint64 `json:"id"`
+	}{r.board, r.id})
+}
+
 // Redirect closes any open posts and forces the client to sync to the target
 // board
 func (c *Client) Redirect(board string) {
 	select {
-	case c.redirect <- board:
+	case c.redirect <- redirection{board: board}:
+	default:
+	}
+}
+
+// RedirectThread closes any open posts and forces the client to sync to the
+// target thread
+func (c *Client) RedirectThread(board string, id uint64) {
+	select {
+	case c.redirect <- redirection{board, id}:
 	default:
 	}
 }
@@ -347,3 +376,23 @@ func (c *Client) Redirect(board string) {
 func (c *Client) IP() string {
 	return c.ip
 }
+
+// UserID returns the account ID of the client or an empty string, if the
+// client is not logged in. Thread-safe for the same reason as IP.
+func (c *Client) UserID() string {
+	return c.userID
+}
+
+// Read the account ID from the session cookie of the request. Invalid
+// sessions are treated as anonymous.
+func getUserID(req *http.Request) string {
+	cookie, err := req.Cookie("session")
+	if err != nil || len(cookie.Value) != common.LenSession {
+		return ""
+	}
+	ss, err := db.GetSession("", cookie.Value)
+	if err != nil {
+		return ""
+	}
+	return ss.UserID
+}
@@ -317,6 +317,31 @@ func TestSendMessage(t *testing.T) {
 	}
 }
 
+func TestSendRedirect(t *testing.T) {
+	t.Parallel()
+
+	sv := newWSServer(t)
+	defer sv.Close()
+	cl, wcl := sv.NewClient()
+
+	cases := [...]struct {
+		name string
+		r    redirection
+		msg  string
+	}{
+		{"board", redirection{board: "a"}, `37"a"`},
+		{"thread", redirection{"a", 3}, `46{"board":"a","id":3}`},
+	}
+
+	for i := range cases {
+		c := cases[i]
+		if err := cl.sendRedirect(c.r); err != nil {
+			t.Fatal(err)
+		}
+		assertMessage(t, wcl, c.msg)
+	}
+}
+
 func TestPinging(t *testing.T) {
 	old := pingTimer
 	pingTimer = time.Millisecond
@@ -39,9 +39,17 @@ interface StaffRecord {
 
 type Staff = StaffRecord[];
 
+const enum BanScope {
+    ip,
+    range,
+    uniqueID,
+}
+
 interface BanRecord {
     ip: string;
     board: string;
+    uniqueID?: string;
+    scope: BanScope;
     id: number;
     by: string;
     expires: number;
@@ -58,6 +66,17 @@ const enum ModerationAction {
     spoilerImage,
     deleteThread,
     updateBoard,
+    selfDeletePost,
+    stickyThread,
+    unstickyThread,
+    lockThread,
+    unlockThread,
+    moveThread,
+    mergeThread,
+    acceptAppeal,
+    denyAppeal,
+    undeletePost,
+    nukePosts,
 }
 
 interface ModLogRecord {
@@ -66,6 +85,11 @@ interface ModLogRecord {
     type: ModerationAction;
     by: string;
     created: number;
+    reason?: string;
+    duration?: number;
+    postBody?: string;
+    postFiles?: string[];
+    postIDs?: number[];
 }
 
 type ModLogRecords = ModLogRecord[];
@@ -557,6 +581,12 @@ class Log extends Component<LogProps, {}> {
                 <a class="admin-content-anchor" name="log" />
                 <h3 class="admin-content-header">
                     <a class="admin-header-link" href="#log">{_("Mod log")}</a>
+                    <a class="admin-log-export" href={`/api/mod-log/${board}/export?format=csv`}>
+                        CSV
+                    </a>
+                    <a class="admin-log-export" href={`/api/mod-log/${board}/export?format=json`}>
+                        JSON
+                    </a>
                 </h3>
                 <table class="admin-table admin-log-list">
                     <thead>
@@ -568,10 +598,12 @@ class Log extends Component<LogProps, {}> {
                         </tr>
                     </thead>
                     <tbody>
-                        {log.map(({ id, type, by, created }) =>
+                        {log.map(({ id, type, by, created, reason, postBody }) =>
                             <tr class="admin-table-item admin-log-item">
                                 <td class="admin-log-id">{this.renderLink(id, type)}</td>
-                                <td class="admin-log-type">{this.renderType(type)}</td>
+                                <td class="admin-log-type" title={[reason, postBody].filter(Boolean).join("\n")}>
+                                    {this.renderType(type)}
+                                </td>
                                 <td class="admin-log-by">{by}</td>
                                 <td class="admin-log-time" title={readableTime(created)}>
                                     {relativeTime(created)}
@@ -625,6 +657,33 @@ class Log extends Component<LogProps, {}> {
                 return <i class="fa fa-2x fa-trash-o" title={_("deleteThread")} />;
             case ModerationAction.updateBoard:
                 return <i class="fa fa-refresh" title={_("updateBoard")} />;
+            case ModerationAction.selfDeletePost:
+                return <i class="fa fa-eraser" title={_("selfDeletePost")} />;
+            case ModerationAction.stickyThread:
+                return <i class="fa fa-thumb-tack" title={_("stickyThread")} />;
+            case ModerationAction.unstickyThread:
+                return (
+                    <span class="fa-stack" title={_("unstickyThread")}>
+                        <i class="fa fa-thumb-tack fa-stack-1x" />
+                        <i class="fa fa-ban fa-stack-2x admin-log-ban-icon" />
+                    </span>
+                );
+            case ModerationAction.lockThread:
+                return <i class="fa fa-lock" title={_("lockThread")} />;
+            case ModerationAction.unlockThread:
+                return <i class="fa fa-unlock" title={_("unlockThread")} />;
+            case ModerationAction.moveThread:
+                return <i class="fa fa-share" title={_("moveThread")} />;
+            case ModerationAction.mergeThread:
+                return <i class="fa fa-compress" title={_("mergeThread")} />;
+            case ModerationAction.acceptAppeal:
+                return <i class="fa fa-check" title={_("acceptAppeal")} />;
+            case ModerationAction.denyAppeal:
+                return <i class="fa fa-times" title={_("denyAppeal")} />;
+            case ModerationAction.undeletePost:
+                return <i class="fa fa-undo" title={_("undeletePost")} />;
+            case ModerationAction.nukePosts:
+                return <i class="fa fa-bomb" title={_("nukePosts")} />;
         }
     }
 }
@@ -79,6 +79,10 @@ export const API = {
         createToken: emit.POST.JSON("post/token"),
         react: (d?: Dict): Promise<SmileReact> => emit.POST.JSON("post/react")(d),
         delete: emit.POST.JSON("delete-post"),
+        undelete: emit.POST.JSON("undelete-post"),
+        getDeleted: (board: string) => emit.GET.JSON(`deleted-posts/${board}`)(),
+        getSameIP: (id: number) => emit.GET.JSON(`same-ip/${id}`)(),
+        nuke: emit.POST.JSON("nuke"),
         get: (id: number) => emit.GET.JSON(`post/${id}`)(),
     },
     smiles: {
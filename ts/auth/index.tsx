@@ -697,8 +697,17 @@ function deletePost(post: Post, force?: boolean) {
   }, showAlert);
 }
 
+const BAN_SCOPES = ["ip", "range", "uniqueID"];
+
 function banUser(post: Post) {
-  if (!confirm(_("banConfirm"))) return;
+  // Ban scope doubles as confirmation.
+  let scope = prompt(_("banConfirm") + "\n" + _("banScope"), "ip");
+  if (scope === null) return;
+  scope = scope.trim() || "ip";
+  if (!BAN_SCOPES.includes(scope)) {
+    showAlert(_("banScope"));
+    return;
+  }
   /* const YEAR = 365 * 24 * 60; */
   const MONTH = 31 * 24 * 60;
   API.user.banByPost({
@@ -707,6 +716,7 @@ function banUser(post: Post) {
     global: position >= ModerationLevel.admin,
     ids: [post.id],
     reason: "default",
+    scope,
   }).then(() => {
     deletePost(post, true);
   }).catch(showAlert);
@@ -3,13 +3,16 @@
  */
 
 import { showAlert } from "../alerts";
-import { PostData, SmileReact } from "../common";
+import _, { ngettext } from "../lang";
+import { Command, PostData, PostLink, SmileReact } from "../common";
 import { connEvent, connSM, handlers, message } from "../connection";
 import { isHoverActive, Post, PostView, observePost } from "../posts";
 import { page, posts, Smile } from "../state";
-import { postAdded } from "../ui";
-import { isAtBottom, scrollToBottom } from "../util";
-import { isFirefox, isLinux, isWebkit } from "../vars";
+import { notifyAboutRemoteReply, postAdded, ReplyNotification } from "../ui";
+import { HOOKS, isAtBottom, scrollToBottom, trigger } from "../util";
+import {
+  isFirefox, isLinux, isWebkit, REPLY_CONTAINER_SEL, THREAD_NAV_REPLY_SEL,
+} from "../vars";
 import { updateBoardSmiles } from "../page/common";
 
 // Run a function on a model, if it exists
@@ -71,6 +74,37 @@ export function insertPost(data: PostData) {
   observePost(view.el)
 }
 
+// Render a post restored by a moderator at its place in the thread
+function restorePost(data: PostData) {
+  if (!page.thread || posts.get(data.id)) return;
+
+  const model = new Post(data);
+  model.op = page.thread;
+  model.board = page.board;
+  posts.add(model);
+  const view = new PostView(model, null);
+  view.afterRender();
+
+  model.propagateLinks();
+
+  // Insert before the first later post
+  const thread = document.getElementById("thread-container").firstChild as Element;
+  const next = Array.from(thread.querySelectorAll(":scope > article[data-id]"))
+    .find((el) => +(el as HTMLElement).dataset.id > data.id);
+  if (next) {
+    next.before(view.el);
+  } else {
+    thread.lastElementChild.after(view.el);
+  }
+  smileLineOffset(view.el.querySelectorAll(".post-message p"));
+  const { reacts = []} = data;
+  reacts.forEach((react: SmileReact) => {
+    view.setReaction(react);
+  });
+
+  observePost(view.el)
+}
+
 function smileLineOffsetJob() {
   const elems = document.querySelectorAll(".post-message p");
   smileLineOffset(elems);
@@ -99,6 +133,89 @@ export function smileLineOffset(elems: any) {
   }
 }
 
+interface PollUpdate {
+  id: number;
+  votes: number[];
+}
+
+// Render new vote tallies into the server-rendered thread poll
+function updatePoll({ id, votes }: PollUpdate) {
+  const el = document.querySelector(`.poll[data-id="${id}"]`);
+  if (!el) return;
+  const total = votes.reduce((a, b) => a + b, 0);
+  votes.forEach((n, i) => {
+    const opt = el.querySelector(`.poll-option[data-index="${i}"]`);
+    if (!opt) return;
+    const percent = total ? Math.floor(n * 100 / total) : 0;
+    opt.querySelector(".poll-option-votes").textContent = n.toString();
+    (opt.querySelector(".poll-option-bar") as HTMLElement).style.width = `${percent}%`;
+  });
+  el.querySelector(".poll-total").textContent =
+    `${total} ${ngettext("vote", "votes", total)}`;
+}
+
+// Disable or re-enable replying to the current thread after it was locked,
+// unlocked or archived
+export function setThreadClosed(closed: boolean) {
+  if (!page.thread) return;
+  if (!closed && !document.querySelector(REPLY_CONTAINER_SEL)) {
+    // Page was rendered without the reply form
+    location.reload();
+    return;
+  }
+  for (const el of document.querySelectorAll(THREAD_NAV_REPLY_SEL)) {
+    (el as HTMLElement).style.display = closed ? "none" : "";
+  }
+  trigger(HOOKS.closeThread, closed);
+}
+
+// Show the number of unread posts of a watched thread next to its subject
+function updateWatched({ id, unread }: WatchedMessage) {
+  if (id === page.thread) return;
+  const header = document.querySelector(`#post${id} .post-header`);
+  if (!header) return;
+  let el = header.querySelector(".post-watched") as HTMLElement;
+  if (!unread) {
+    if (el) el.remove();
+    return;
+  }
+  if (!el) {
+    el = document.createElement("span");
+    el.className = "post-header-item post-watched";
+    el.title = _("watchedUnread");
+    const anchor = header.querySelector(".post-id");
+    header.insertBefore(el, anchor ? anchor.nextSibling : null);
+  }
+  el.textContent = `+${unread}`;
+}
+
+interface LockMessage {
+  id: number;
+  locked: boolean;
+}
+
+interface EditMessage {
+  id: number;
+  body: string;
+  links?: PostLink[];
+  commands?: Command[];
+}
+
+interface BacklinkMessage {
+  id: number;
+  link: PostLink;
+}
+
+interface RedirectThreadMessage {
+  board: string;
+  id: number;
+}
+
+interface WatchedMessage {
+  id: number;
+  unread: number;
+}
+
 export function init() {
   handlers[message.invalid] = (msg: string) => {
     showAlert(msg);
@@ -110,10 +227,19 @@ export function init() {
 
   handlers[message.deletePost] = (id: number) => handle(id, (m) => m.setDeleted());
 
+  handlers[message.undeletePost] = restorePost;
+
+  handlers[message.editPost] = ({ id, body, links, commands }: EditMessage) =>
+    handle(id, (m) => m.setBody(body, links, commands));
+
   handlers[message.redirect] = (board: string) => {
     location.href = `/${board}/`;
   };
 
+  handlers[message.redirectThread] = ({ board, id }: RedirectThreadMessage) => {
+    location.href = `/${board}/${id}`;
+  };
+
   interface SmileUpdate {
     board: string;
     deleted: boolean;
@@ -125,9 +251,31 @@ export function init() {
     updateBoardSmiles(d.board);
   }
 
+  handlers[message.pollUpdated] = updatePoll;
+
+  handlers[message.threadLocked] = ({ id, locked }: LockMessage) => {
+    if (id === page.thread) {
+      setThreadClosed(locked);
+    }
+  };
+
+  handlers[message.threadArchived] = (id: number) => {
+    if (id === page.thread) {
+      setThreadClosed(true);
+    }
+  };
+
+  handlers[message.backlink] = ({ id, link }: BacklinkMessage) =>
+    handle(id, (m) => m.insertBacklink(link[0], link[1]));
+
+  handlers[message.watchedThread] = updateWatched;
+
   // handlers[message.notification] = (text: string) =>
   //   new OverlayNotification(text);
 
+  handlers[message.replyNotification] = (n: ReplyNotification) =>
+    notifyAboutRemoteReply(n);
+
   // handlers[message.insertImage] = (msg: ImageMessage) =>
   //   handle(msg.id, (m) => {
   //     delete msg.id;
@@ -20,9 +20,11 @@ export interface PostData {
   id: number;
   time: number;
   auth?: string;
+  sage?: boolean;
   userID?: string;
   userName?: string;
   userColor?: string;
+  trip?: string;
   body: string;
   links?: PostLink[];
   commands?: Command[];
@@ -40,10 +42,11 @@ export interface SmileReact {
 }
 
 /** Generic link object containing target post board and thread. */
-export type PostLink = [number, number];
+export type PostLink = [number, number, string?];
 
 /** Possible command types. */
-export const enum commandType { roll, flip }
+// MUST BE KEPT IN SYNC WITH go/src/meguca/common/commands.go!
+export const enum commandType { roll, flip, dice, eightBall, countdown, pick }
 
 /** Single command result delivered from the server. */
 export interface Command {
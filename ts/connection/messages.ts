@@ -15,6 +15,9 @@ export const enum message {
   deletePost,
   banned,
   deleteImage,
+  reacted,
+  editPost,
+  undeletePost,
 
   // >= 30 are miscellaneous and do not write to post models
   synchronise = 30,
@@ -45,6 +48,27 @@ export const enum message {
   // Notification about needing a captcha on the next post allocation
   captcha,
   smilesUpdate,
+
+  // Thread was moved to the archive and is read-only now
+  threadArchived,
+
+  // Thread was locked or unlocked by a moderator
+  threadLocked,
+
+  // Updated vote tallies of a thread poll
+  pollUpdated,
+
+  // Post in another thread linked an existing post
+  backlink,
+
+  // Post counter of a watched thread changed
+  watchedThread,
+
+  // Redirect the client to a specific thread
+  redirectThread,
+
+  // Reply to a post of the client's account
+  replyNotification,
 }
 
 // TODO(Kagami): Use proper message type (need to fix handler
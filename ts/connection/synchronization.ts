@@ -1,6 +1,6 @@
 import { showAlert } from "../alerts";
 import API from "../api";
-import { insertPost } from "../client";
+import { insertPost, setThreadClosed } from "../client";
 import { page, posts } from "../state";
 import { handlers, message } from "./messages";
 import { connEvent, connSM, send } from "./state";
@@ -17,6 +17,8 @@ interface SyncData {
   deletedImage: number[]; // Posts deleted in this thread
   banned: number[]; // Posts banned in this thread
   reacts: SmileReact[]
+  locked?: boolean; // Thread is locked by a moderator
+  archived?: boolean; // Thread is moved to the archive
 }
 
 // State of an open post
@@ -66,9 +68,13 @@ handlers[message.synchronise] = async (data: SyncData) => {
 
   // Board pages currently have no sync data
   if (data) {
-    const { recent = [], deleted = [], reacts = [] } = data;
+    const { recent = [], deleted = [], reacts = [], locked, archived } = data;
     const proms: Array<Promise<void>> = [];
 
+    if (locked || archived) {
+      setThreadClosed(true);
+    }
+
     for (const id of recent) {
       if (id >= minID && !posts.has(id)) {
         // FIXME(Kagami): Remove deleted posts from recent.
@@ -1,6 +1,6 @@
 import { addHasReplyClass } from './../page/common';
 import { Model } from "../base";
-import { fileTypes, ImageData, PostData, PostLink, SmileReact } from "../common";
+import { Command, fileTypes, ImageData, PostData, PostLink, SmileReact } from "../common";
 import { mine, page, posts } from "../state";
 import { notifyAboutReply } from "../ui";
 import Collection from "./collection";
@@ -32,11 +32,14 @@ export class Post extends Model implements PostData {
 
   public time: number;
   public auth?: string;
+  public sage?: boolean;
   public userID?: string;
   public userName?: string;
   public userColor?: string;
+  public trip?: string;
   public body: string;
   public links?: PostLink[];
+  public commands?: Command[];
   public files?: ImageData[];
   public reacts?: SmileReact[];
   public backlinks: PostBacklinks;
@@ -118,6 +121,21 @@ export class Post extends Model implements PostData {
     this.view.renderBacklinks();
   }
 
+  // Replace the body of the post after an edit and set backlinks on any
+  // newly linked posts.
+  public setBody(body: string, links?: PostLink[], commands?: Command[]) {
+    this.body = body;
+    this.links = links;
+    this.commands = commands;
+    this.view.renderBody();
+    for (const [id] of links || []) {
+      const post = posts.get(id);
+      if (post) {
+        post.insertBacklink(this.id, this.op);
+      }
+    }
+  }
+
   // Set post as deleted.
   public setDeleted() {
     if (this.isOP()) {
@@ -367,6 +367,7 @@ class Reply extends Component<any, any> {
     smileBoxAC: null as string[],
     fwraps: [] as FWraps,
     showBadge: false,
+    sage: false,
   };
   private mainEl: HTMLElement = null;
   private bodyEl: HTMLTextAreaElement = null;
@@ -943,7 +944,7 @@ class Reply extends Component<any, any> {
   }
   private handleSend = () => {
     if (this.disabled) return;
-    const { board, thread, subject, body, showBadge } = this.state;
+    const { board, thread, subject, body, showBadge, sage } = this.state;
     const files = this.state.fwraps.map((f) => f.file);
     const sendFn = page.thread ? API.post.create : API.thread.create;
     this.setState({ sending: true });
@@ -959,6 +960,7 @@ class Reply extends Component<any, any> {
             body,
             files,
             showBadge,
+            sage,
             token,
             sign,
           },
@@ -971,6 +973,9 @@ class Reply extends Component<any, any> {
           if (page.thread) {
             storeMine(res.id, page.thread);
             this.handleFormHide();
+            if (res.held) {
+              showAlert({ title: _("postHeld"), message: _("postHeldMessage"), type: "neutral" });
+            }
           } else {
             storeMine(res.id, res.id);
             location.href = `/${board}/${res.id}`;
@@ -1003,6 +1008,10 @@ class Reply extends Component<any, any> {
     const showBadge = !this.state.showBadge;
     this.setState({ showBadge }, this.focus);
   }
+  private handleToggleSage = () => {
+    const sage = !this.state.sage;
+    this.setState({ sage }, this.focus);
+  }
   private handleToggleSmileBox = (e: MouseEvent) => {
     // Needed because of https://github.com/developit/preact/issues/838
     e.stopPropagation();
@@ -1116,7 +1125,7 @@ class Reply extends Component<any, any> {
     );
   }
   private renderFooterControls() {
-    const { editing, sending, progress, showBadge } = this.state;
+    const { editing, sending, progress, showBadge, sage } = this.state;
     const sendTitle = sending ? `${_("clickToCancel")}` : "";
     return (
       <div class="reply-controls reply-footer-controls">
@@ -1192,6 +1201,18 @@ class Reply extends Component<any, any> {
             <i class="fa fa-id-badge" />
           </button>
         )}
+        {page.thread && (
+          <button
+            class={cx("control", "reply-footer-control", "reply-sage-control", {
+              control_active: sage,
+            })}
+            title={_("sagePost")}
+            disabled={sending}
+            onClick={this.handleToggleSage}
+          >
+            <i class="fa fa-arrow-down" />
+          </button>
+        )}
 
         <div
           /* class="reply-dragger" */
@@ -1265,8 +1286,12 @@ class ReplyContainer extends Component<any, any> {
     show: false,
     quoted: null as Element,
     dropped: null as FileList,
+    closed: false,
   };
   public componentDidMount() {
+    hook(HOOKS.closeThread, (closed: boolean) => {
+      this.setState({ show: false, closed });
+    });
 
     hook(HOOKS.openReply, () => {
       this.setState({ show: true });
@@ -1330,8 +1355,8 @@ class ReplyContainer extends Component<any, any> {
       }
     });
   }
-  public render({ }, { show, quoted, dropped }: any) {
-    return show ? (
+  public render({ }, { show, quoted, dropped, closed }: any) {
+    return show && !closed ? (
       <Reply quoted={quoted} dropped={dropped} onHide={this.handleHide} />
     ) : null;
   }
@@ -5,10 +5,10 @@ import options from "../options";
 import { page, getSmileByItsName, loadSmilesWithGlobal } from "../state";
 import {
     makePostContext, readableTime,
-    relativeTime, renderPostLink, TemplateContext,
+    relativeTime, renderBody, renderPostLink, TemplateContext,
 } from "../templates";
 import { getID, createElement } from "../util";
-import { POST_BACKLINKS_SEL, THREAD_SEL } from "../vars";
+import { POST_BACKLINKS_SEL, POST_MESSAGE_SEL, THREAD_SEL } from "../vars";
 import { render as renderEmbeds } from "./embed";
 import { Post, Thread } from "./model";
 import { getRecent } from "./smile-box";
@@ -87,13 +87,20 @@ export default class PostView extends View<Post> {
         el.textContent = text;
     }
 
+    // Re-render the post body, e.g. after it was edited.
+    public renderBody(): Promise<void> {
+        const el = this.el.querySelector(POST_MESSAGE_SEL);
+        el.innerHTML = renderBody(this.model);
+        return renderEmbeds(this.el);
+    }
+
     // Render links to posts linking to this post.
     public renderBacklinks() {
         const index = !page.thread;
         const rendered = Object.keys(this.model.backlinks).map((id) => {
             const op = this.model.backlinks[id];
             const cross = op !== this.model.op;
-            return renderPostLink(+id, cross, index);
+            return renderPostLink(+id, "", cross, index);
         });
         if (!rendered.length) return;
 
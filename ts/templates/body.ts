@@ -4,8 +4,8 @@
 // MUST BE KEPT IN SYNC WITH go/src/meguca/templates/body.go!
 
 import { renderPostLink } from "."; // TODO(Kagami): Avoid circular import
-import { Command, PostData, PostLink } from "../common";
-import { page, loadSmilesWithGlobal } from "../state";
+import { Command, commandType, PostData, PostLink } from "../common";
+import { boards, page, loadSmilesWithGlobal } from "../state";
 import { escape, unescape } from "../util";
 import marked from "./marked";
 
@@ -15,18 +15,29 @@ interface AnyClass {
 
 const noop = marked.noop;
 
-// Verify and render a link to other posts.
-function postLink(m: RegExpMatchArray, links: PostLink[], tid: number): string {
-    if (!links) return escape(m[0]);
+const postLinkRe = /^>>(?:>\/([a-z0-9]{1,10})\/)?(\d*)$/;
 
-    const id = +m[0].slice(2);
-    const link = links.find((l) => l[0] === id);
+// Verify and render a link to other posts or boards.
+function postLink(m: RegExpMatchArray, post: PostData): string {
+    const [, board, idStr] = m[0].match(postLinkRe);
+    if (!idStr) {
+        if (!boards.some((b) => b.id === board)) return escape(m[0]);
+        return `<a class="board-link" href="/${board}/">&gt;&gt;&gt;/${board}/</a>`;
+    }
+    if (!post.links) return escape(m[0]);
+
+    const id = +idStr;
+    const link = post.links.find((l) => l[0] === id);
     if (!link) return escape(m[0]);
+    const linkBoard = link[2] || "";
+    if (board && board !== linkBoard) return escape(m[0]);
 
     const op = link[1];
-    const cross = op !== tid;
+    const cross = op !== post.op;
     const index = !page.thread;
-    return renderPostLink(id, cross, index);
+    // Only show board prefix for links leading outside of current board.
+    const prefix = linkBoard !== (post.board || page.board) ? linkBoard : "";
+    return renderPostLink(id, prefix, cross, index);
 }
 
 class CustomLexer extends ((marked as any).Lexer as AnyClass) {
@@ -55,13 +66,13 @@ class CustomInlineLexer extends ((marked as any).InlineLexer as AnyClass) {
             del: /^%%([\s\S]+?)%%/,
             link: noop,
             nolink: noop,
-            reflink: /^>>\d+()/,
+            reflink: /^>>(?:>\/[a-z0-9]{1,10}\/\d*|\d+)()/,
             text: new RegExp(textSrc.replace("]|", ">%]|")),
         });
     }
     protected outputLink(cap: any, link: any) {
         if (link.href === "post-link") {
-            return postLink(cap, this.post.links, this.post.op);
+            return postLink(cap, this.post);
         }
         return super.outputLink(cap, link);
     }
@@ -112,12 +123,85 @@ export const linkEmbeds: { [key: string]: RegExp } = (() => {
     return m;
 })();
 
+const rollQueryRe = /^(0|[1-9][0-9]?)-([1-9][0-9]?[0-9]?)$/;
+const flipQueryRe = /^([1-9][0-9]?)%$/;
+const diceQueryRe = /^([1-9]|10)d([1-9][0-9]?[0-9]?)([+-][1-9][0-9]?[0-9]?)?$/;
+const countdownQueryRe = /^[1-9][0-9]{0,10}$/;
+
+// Type of command c with query q, if it's valid.
+function matchCommand(c: string, q: string): commandType | null {
+    switch (c) {
+    case "roll":
+        if (rollQueryRe.test(q)) return commandType.roll;
+        if (diceQueryRe.test(q)) return commandType.dice;
+        break;
+    case "flip":
+        if (flipQueryRe.test(q)) return commandType.flip;
+        break;
+    case "8ball":
+        if (q === "") return commandType.eightBall;
+        break;
+    case "countdown":
+        if (countdownQueryRe.test(q)) return commandType.countdown;
+        break;
+    case "pick":
+        if (q === "") return commandType.pick;
+        break;
+    }
+    return null;
+}
+
+// Render the result of a single post command.
+function renderCommand(cmd: Command, text: string, q: string): string {
+    switch (cmd.type) {
+    case commandType.roll:
+        return `<i class="fa fa-cube post-command post-roll-command"` +
+               ` title="${text}"> ${cmd.val} (${q})</i>`;
+    case commandType.flip:
+        return `<i class="fa fa-cube post-command post-flip-command ` +
+               `post-flip-command_${cmd.val ? "hit" : "miss"}"` +
+               ` title="${text}"> ${q}</i>`;
+    case commandType.dice: {
+        const rolls = cmd.val as number[];
+        let total = rolls.reduce((a, b) => a + b, 0);
+        const mod = q.match(diceQueryRe)[3];
+        if (mod) {
+            total += +mod;
+        }
+        return `<i class="fa fa-cube post-command post-dice-command"` +
+               ` title="${text}"> ${total} (${q}: ${rolls.join(", ")})</i>`;
+    }
+    case commandType.eightBall:
+        return `<i class="fa fa-question-circle post-command post-8ball-command"` +
+               ` title="${text}"> ${escape(cmd.val)}</i>`;
+    case commandType.countdown: {
+        const date = new Date(cmd.val * 1000).toISOString();
+        const formatted = date.slice(0, 16).replace("T", " ") + " UTC";
+        return `<i class="fa fa-clock-o post-command post-countdown-command"` +
+               ` title="${text}" data-time="${q}"> ${formatted}</i>`;
+    }
+    case commandType.pick:
+        return `<i class="fa fa-list post-command post-pick-command"` +
+               ` title="${text}"> ${escape(cmd.val)}</i>`;
+    }
+    return escape(text);
+}
+
 class CustomRenderer extends ((marked as any).Renderer as AnyClass) {
     constructor(commands: Command[]) {
         super();
         this.commands = commands;
         this.cmdi = 0;
     }
+    public command(text: string, c: string, q: string): string {
+        const type = matchCommand(c, q);
+        const cmd = this.commands && this.commands[this.cmdi];
+        if (type === null || !cmd || cmd.type !== type) {
+            return escape(text);
+        }
+        this.cmdi++;
+        return renderCommand(cmd, text, q);
+    }
     public blockquote(quote: string): string {
         return "<blockquote>&gt; " + quote + "</blockquote>";
     }
@@ -46,7 +46,10 @@ export function makePostContext(
     Subject: p.subject,
     Badge: !!p.auth,
     Auth: _(p.auth),
+    Sage: !!p.sage,
+    LSage: _("sage"),
     Name: p.userName,
+    Trip: p.trip,
     Reacts: p.reacts,
     Color: p.userColor,
     ShowColor: !!p.userColor,
@@ -162,10 +165,12 @@ export function fileSize(size: number): string {
   }
 }
 
-// Render a link to other post.
-export function renderPostLink(id: number, cross: boolean, index: boolean): string {
+// Render a link to other post. Can optionally be prefixed with the board
+// of the post.
+export function renderPostLink(id: number, board: string, cross: boolean, index: boolean): string {
   const url = `${cross || index ? `/all/${id}` : ""}#${id}`
   return new TemplateContext("post-link", {
+    Board: board,
     Cross: cross,
     ID: id,
     LYou: _("you"),
@@ -461,7 +461,7 @@ var inline = {
   br: /^ {2,}\n(?!\s*$)/,
   del: noop,
   smile: /^:([a-z0-9_]+):/,
-  command: /^!(roll(?:0|[1-9][0-9]?)-[1-9][0-9]?[0-9]?|flip[1-9][0-9]?%)/,
+  command: /^!(roll|flip|8ball|countdown|pick)([\w+%-]*)/,
   text: /^[\s\S]+?(?=[\\<!\[_*`:]| {2,}\n|$)/
 };
 
@@ -696,7 +696,7 @@ InlineLexer.prototype.output = function(src) {
     // command (cutechan)
     if (cap = this.rules.command.exec(src)) {
       src = src.substring(cap[0].length);
-      out += this.renderer.command(cap[0], cap[1].slice(0, 4), cap[1].slice(4));
+      out += this.renderer.command(cap[0], cap[1], cap[2]);
       continue;
     }
 
@@ -890,20 +890,9 @@ Renderer.prototype.smile = function(s) {
   return `<img class="smile" src="${smilePath(s.fileType, s.sha1)}" title=":${s.name}: (${s.board})">`
 };
 
+// Overridden by the post body renderer.
 Renderer.prototype.command = function(text, c, q) {
-  if (!this.commands || this.cmdi >= this.commands.length) return escape(text);
-  const cmd = this.commands[this.cmdi];
-  switch (c) {
-  case "roll":
-    this.cmdi++;
-    return `<i class="fa fa-cube post-command post-roll-command"` +
-              `title="${text}"> ${cmd.val} (${q})</i>`;
-  case "flip":
-    this.cmdi++;
-    return `<i class="fa fa-cube post-command post-flip-command ` +
-                     `post-flip-command_${cmd.val ? "hit" : "miss"}"` +
-              `title="${text}"> ${q}</i>`;
-  }
+  return escape(text);
 };
 
 Renderer.prototype.link = function(href, title, text) {
@@ -2,7 +2,7 @@ import { View } from "../base";
 import _ from "../lang";
 import options from "../options";
 import { Post } from "../posts";
-import { mine } from "../state";
+import { mine, page } from "../state";
 import { DEFAULT_NOTIFICATION_IMAGE_URL } from "../vars";
 import { repliedToMe } from "./tab";
 
@@ -49,6 +49,44 @@ export default function notifyAboutReply(post: Post) {
   }, 5000);
 }
 
+// Reply to a post of the logged in account, as sent by the server.
+export interface ReplyNotification {
+  id: number;
+  post: number;
+  op: number;
+  board: string;
+  target: number;
+  time: number;
+  read: boolean;
+}
+
+// Notify the user about a reply in another thread. Replies in the current
+// thread are handled on post insertion.
+export function notifyAboutRemoteReply(n: ReplyNotification) {
+  if (options.doNotDisturb) return;
+  if (n.op === page.thread) return;
+  if (!options.notification
+      || typeof Notification !== "function"
+      || (Notification as any).permission !== "granted"
+  ) return;
+
+  const url = `/${n.board}/${n.op}#${n.post}`;
+  const notif = new Notification(_("replied"), {
+    body: `>>${n.target}`,
+    requireInteraction: false,
+    renotify: false,
+    icon: DEFAULT_NOTIFICATION_IMAGE_URL,
+  } as any);
+  notif.onclick = () => {
+    notif.close();
+    window.focus();
+    location.href = url;
+  };
+  setTimeout(() => {
+    notif.close();
+  }, 5000);
+}
+
 // Textual notification at the top of the page
 // TODO(Kagami): Rework.
 export class OverlayNotification extends View<null> {
@@ -19,6 +19,7 @@ export const enum HOOKS {
   spoilerMarkup,
   focusIdolSearch,
   openIgnoreModal,
+  closeThread,
 }
 
 const hooks = new EventEmitter();
@@ -11,6 +11,7 @@ export const REACTION_HOVER_CONTAINER_SEL = ".reaction-container";
 export const POPUP_CONTAINER_SEL = ".popup-container";
 export const MODAL_CONTAINER_SEL = ".modal-container";
 export const REPLY_CONTAINER_SEL = ".reply-container";
+export const THREAD_NAV_REPLY_SEL = ".thread-nav-reply";
 export const PROFILES_CONTAINER_SEL = ".header-profiles";
 export const BOARD_SEARCH_INPUT_SEL = ".board-search-input";
 export const BOARD_SEARCH_SORT_SEL = ".board-search-sort";
@@ -24,6 +25,7 @@ export const POST_FILE_THUMB_SEL = ".post-file-thumb";
 export const POST_FILE_THUMB_CONT_SEL = ".post-file-thumb_containter";
 export const POST_FILE_THUMB_BG_SEL = ".post-file-thumb_background";
 export const POST_BACKLINKS_SEL = ".post-backlinks";
+export const POST_MESSAGE_SEL = ".post-message";
 export const POST_EMBED_SEL = ".post-embed";
 export const POST_EMBED_INSTAGRAM_SEL = ".post-instagram-embed";
 export const POST_EMBED_TWITTER_SEL = ".post-twitter-embed";